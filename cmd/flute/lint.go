@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+// errLint is returned when the fixture files have configuration mistakes.
+var errLint = errors.New("the fixture files have mistakes")
+
+func lint(args []string, stdout, stderr io.Writer) error {
+	var files stringsFlag
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Var(&files, "c", "fixture file. This flag can be specified multiple times")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("fixture files are required (-c)")
+	}
+	services, err := loadFixtures(files)
+	if err != nil {
+		return err
+	}
+	if err := (flute.Transport{Services: services}).Validate(); err != nil {
+		fmt.Fprintln(stdout, err)
+		return errLint
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_lint(t *testing.T) {
+	data := []struct {
+		title   string
+		fixture string
+		exp     string
+		isErr   bool
+	}{
+		{
+			title: "valid",
+			fixture: `services:
+  - endpoint: http://example.com
+    routes:
+      - name: create a user
+        matcher:
+          method: POST
+          path: /users
+`,
+		},
+		{
+			title: "mistakes",
+			fixture: `services:
+  - endpoint: http://example.com/api
+    routes:
+      - name: create a user
+        matcher:
+          method: POST
+      - name: create a user
+        matcher:
+          method: POST
+          path: /users
+`,
+			exp: `service http://example.com/api: the endpoint must consist of the scheme and host only: http://example.com/api
+route 1 (create a user): the route name is the same as route 0
+route 1 (create a user): the route is unreachable because route 0 (create a user) matches all requests which the route matches
+`,
+			isErr: true,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mocks.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(d.fixture), 0o600))
+			stdout := &bytes.Buffer{}
+			err := lint([]string{"-c", path}, stdout, io.Discard)
+			if d.isErr {
+				require.ErrorIs(t, err, errLint)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, d.exp, stdout.String())
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// flute is the command to serve and lint flute's fixtures.
+//
+//	flute serve -c mocks.yaml -addr :8080
+//	flute lint -c mocks.yaml
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const usage = `flute - serve and lint flute's fixtures
+
+Usage:
+  flute serve -c <fixture file> [-c <fixture file> ...] [-addr :8080] [-endpoint <endpoint>] [-interval 1s] [-v]
+  flute lint -c <fixture file> [-c <fixture file> ...]
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 1
+	}
+	switch args[0] {
+	case "serve":
+		if err := serve(ctx, args[1:], stderr); err != nil {
+			fmt.Fprintf(stderr, "flute serve: %v\n", err)
+			return 1
+		}
+		return 0
+	case "lint":
+		if err := lint(args[1:], stdout, stderr); err != nil {
+			fmt.Fprintf(stderr, "flute lint: %v\n", err)
+			return 1
+		}
+		return 0
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stderr, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command: %s\n%s", args[0], usage)
+		return 1
+	}
+}
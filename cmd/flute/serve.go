@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+// stringsFlag is a flag which can be specified multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+func serve(ctx context.Context, args []string, stderr io.Writer) error {
+	var files stringsFlag
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Var(&files, "c", "fixture file. This flag can be specified multiple times")
+	addr := fs.String("addr", ":8080", "address to listen on")
+	endpoint := fs.String("endpoint", "", `scheme and host which the requests are sent to, such as "http://example.com". By default, the Host header is used`)
+	verbose := fs.Bool("v", false, "log each route tried and the condition which rejects the route")
+	interval := fs.Duration("interval", time.Second, "interval to check whether the fixture files are changed. If it is zero, the fixture files aren't reloaded")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("fixture files are required (-c)")
+	}
+	logger := log.New(stderr, "", log.LstdFlags)
+
+	// get the modification times before loading not to miss changes during loading
+	mtimes := modTimes(files)
+	services, err := loadFixtures(files)
+	if err != nil {
+		return err
+	}
+	base := flute.Transport{
+		Services: services,
+	}
+	if *verbose {
+		base.Logger = slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		}))
+	}
+	transport := flute.NewDynamicTransport(base)
+	if *interval > 0 {
+		go watchFixtures(ctx, files, mtimes, *interval, transport, logger)
+	}
+
+	server := &http.Server{
+		Addr: *addr,
+		Handler: flute.Handler{
+			Transport: transport,
+			Endpoint:  *endpoint,
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Printf("shut down the server: %v", err)
+		}
+	}()
+	logger.Printf("serving %s on %s", strings.Join(files, ", "), *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+	return nil
+}
+
+// loadFixtures loads the fixture files and returns all services.
+func loadFixtures(files []string) ([]flute.Service, error) {
+	var services []flute.Service
+	for _, file := range files {
+		s, err := flute.LoadFixture(file)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, s...)
+	}
+	return services, nil
+}
+
+// watchFixtures reloads the fixture files when their modification times are changed from mtimes.
+// If the fixture files can't be loaded, the current services are kept.
+func watchFixtures(ctx context.Context, files []string, mtimes []time.Time, interval time.Duration, transport *flute.DynamicTransport, logger *log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current := modTimes(files)
+		if equalModTimes(mtimes, current) {
+			continue
+		}
+		mtimes = current
+		services, err := loadFixtures(files)
+		if err != nil {
+			logger.Printf("failed to reload the fixture files: %v", err)
+			continue
+		}
+		transport.SetServices(services...)
+		logger.Printf("reloaded the fixture files")
+	}
+}
+
+// modTimes returns the modification times of the files.
+// If the file can't be got, the modification time is zero.
+func modTimes(files []string) []time.Time {
+	mtimes := make([]time.Time, len(files))
+	for i, file := range files {
+		if fi, err := os.Stat(file); err == nil {
+			mtimes[i] = fi.ModTime()
+		}
+	}
+	return mtimes
+}
+
+func equalModTimes(a, b []time.Time) bool {
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func writeFixture(t *testing.T, path string, statusCode int, mtime time.Time) {
+	t.Helper()
+	b := fmt.Appendf(nil, `services:
+  - endpoint: http://example.com
+    routes:
+      - name: get a user
+        response:
+          status: %d
+`, statusCode)
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func statusCode(transport *flute.DynamicTransport) int {
+	services := transport.Services()
+	if len(services) == 0 || len(services[0].Routes) == 0 {
+		return 0
+	}
+	return services[0].Routes[0].Response.Base.StatusCode
+}
+
+func Test_watchFixtures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mocks.yaml")
+	now := time.Now()
+	writeFixture(t, path, http.StatusOK, now.Add(-time.Minute))
+	mtimes := modTimes([]string{path})
+	services, err := loadFixtures([]string{path})
+	require.NoError(t, err)
+	transport := flute.NewDynamicTransport(flute.Transport{
+		Services: services,
+	})
+	go watchFixtures(t.Context(), []string{path}, mtimes, 10*time.Millisecond, transport, log.New(io.Discard, "", 0))
+
+	writeFixture(t, path, http.StatusServiceUnavailable, now)
+	require.Eventually(t, func() bool {
+		return statusCode(transport) == http.StatusServiceUnavailable
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the invalid fixture is ignored
+	require.NoError(t, os.WriteFile(path, []byte("services: {"), 0o600))
+	require.NoError(t, os.Chtimes(path, now.Add(time.Minute), now.Add(time.Minute)))
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, http.StatusServiceUnavailable, statusCode(transport))
+}
+
+func Test_run(t *testing.T) {
+	data := []struct {
+		title string
+		args  []string
+		code  int
+	}{
+		{
+			title: "no command",
+			code:  1,
+		},
+		{
+			title: "help",
+			args:  []string{"help"},
+		},
+		{
+			title: "unknown command",
+			args:  []string{"foo"},
+			code:  1,
+		},
+		{
+			title: "no fixture file",
+			args:  []string{"serve"},
+			code:  1,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			require.Equal(t, d.code, run(t.Context(), d.args, io.Discard, io.Discard))
+		})
+	}
+}
@@ -62,3 +62,41 @@ func TestClient_CreateUser(t *testing.T) {
 		Email: "foo@example.com",
 	}, user)
 }
+
+func TestClient_CreateUser_builder(t *testing.T) {
+	token := "XXXXX"
+	service, err := flute.NewService("http://example.com").
+		Post("/users").
+		Name("create a user").
+		ExpectHeader("Authorization", "token "+token).
+		ExpectJSON(map[string]any{
+			"name":  "foo",
+			"email": "foo@example.com",
+		}).
+		RespondJSON(http.StatusCreated, map[string]any{
+			"id":    10,
+			"name":  "foo",
+			"email": "foo@example.com",
+		}).
+		Build()
+	require.NoError(t, err)
+	client := &Client{
+		Token: token,
+		HTTPClient: &http.Client{
+			Transport: flute.Transport{
+				T:        t,
+				Services: []flute.Service{service},
+			},
+		},
+	}
+	user, _, err := client.CreateUser(&User{ //nolint:bodyclose
+		Name:  "foo",
+		Email: "foo@example.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &User{
+		ID:    10,
+		Name:  "foo",
+		Email: "foo@example.com",
+	}, user)
+}
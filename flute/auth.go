@@ -0,0 +1,337 @@
+package flute
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	// BasicAuth is the credentials of Basic authentication.
+	BasicAuth struct {
+		Username string
+		Password string
+	}
+
+	// HMACSignature is the condition of the request signature with HMAC.
+	HMACSignature struct {
+		// Key is the secret key.
+		Key []byte
+		// Hash is the hash function. If Hash is nil, sha256.New is used.
+		Hash func() hash.Hash
+		// Header is the request header which has the signature such as "X-Hub-Signature-256".
+		Header string
+		// Prefix is the prefix of the header value such as "sha256=".
+		Prefix string
+		// Encoding is the encoding of the signature. The default is HMACEncodingHex.
+		Encoding HMACEncoding
+		// CanonicalString returns the signed string.
+		// If CanonicalString is nil, the request body is signed.
+		CanonicalString func(req *http.Request, body []byte) string
+	}
+
+	// HMACEncoding is the encoding of the HMAC signature.
+	HMACEncoding int
+
+	// AWSSigV4 is the credentials to verify the request signed with AWS Signature Version 4.
+	// The signature in the Authorization header is verified.
+	AWSSigV4 struct {
+		AccessKeyID     string
+		SecretAccessKey string
+		// If Region isn't empty, the region of the credential scope should match.
+		Region string
+		// If Service isn't empty, the service of the credential scope should match.
+		Service string
+		// DisableURIPathEscaping should be true for Amazon S3,
+		// whose canonical URI isn't escaped twice.
+		DisableURIPathEscaping bool
+	}
+)
+
+const (
+	// HMACEncodingHex encodes the signature as a lowercase hex string.
+	HMACEncodingHex HMACEncoding = iota
+	// HMACEncodingBase64 encodes the signature with the standard base64 encoding.
+	HMACEncodingBase64
+)
+
+const (
+	awsSigV4Algorithm = "AWS4-HMAC-SHA256"
+	awsAmzDateFormat  = "20060102T150405Z"
+)
+
+// verify returns an error if the request isn't authenticated with the credentials.
+func (auth *BasicAuth) verify(req *http.Request) error {
+	username, password, ok := req.BasicAuth()
+	if !ok {
+		return errors.New("the request doesn't have the Basic authentication credentials")
+	}
+	if username != auth.Username {
+		return fmt.Errorf("the username of Basic authentication should be %q but %q", auth.Username, username)
+	}
+	if password != auth.Password {
+		return errors.New("the password of Basic authentication is wrong")
+	}
+	return nil
+}
+
+// bearerToken returns the Bearer token of the request.
+func bearerToken(req *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return token, true
+}
+
+func verifyBearerToken(req *http.Request, expected string) error {
+	token, ok := bearerToken(req)
+	if !ok {
+		return errors.New("the request doesn't have the Bearer token")
+	}
+	if token != expected {
+		return errors.New("the Bearer token is wrong")
+	}
+	return nil
+}
+
+// Sign returns the signature of the request.
+func (sig *HMACSignature) Sign(req *http.Request, body []byte) string {
+	h := sig.Hash
+	if h == nil {
+		h = sha256.New
+	}
+	mac := hmac.New(h, sig.Key)
+	if sig.CanonicalString != nil {
+		mac.Write([]byte(sig.CanonicalString(req, body)))
+	} else {
+		mac.Write(body)
+	}
+	if sig.Encoding == HMACEncodingBase64 {
+		return sig.Prefix + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	return sig.Prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (sig *HMACSignature) verify(req *http.Request, body []byte) error {
+	actual := req.Header.Get(sig.Header)
+	if actual == "" {
+		return fmt.Errorf("the request header %s is required", sig.Header)
+	}
+	if !hmac.Equal([]byte(sig.Sign(req, body)), []byte(actual)) {
+		return fmt.Errorf("the HMAC signature of the request header %s is wrong", sig.Header)
+	}
+	return nil
+}
+
+// awsAuthorization is the parsed Authorization header of AWS Signature Version 4.
+type awsAuthorization struct {
+	accessKeyID   string
+	date          string
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+}
+
+func parseAWSAuthorization(header string) (*awsAuthorization, error) {
+	algorithm, params, ok := strings.Cut(header, " ")
+	if !ok || algorithm != awsSigV4Algorithm {
+		return nil, errors.New("the Authorization header isn't AWS Signature Version 4")
+	}
+	auth := &awsAuthorization{}
+	for param := range strings.SplitSeq(params, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok {
+			return nil, fmt.Errorf("the Authorization header is invalid: %s", param)
+		}
+		switch k {
+		case "Credential":
+			scope := strings.Split(v, "/")
+			if len(scope) != 5 || scope[4] != "aws4_request" {
+				return nil, fmt.Errorf("the credential of the Authorization header is invalid: %s", v)
+			}
+			auth.accessKeyID, auth.date, auth.region, auth.service = scope[0], scope[1], scope[2], scope[3]
+		case "SignedHeaders":
+			auth.signedHeaders = strings.Split(v, ";")
+		case "Signature":
+			auth.signature = v
+		}
+	}
+	if auth.accessKeyID == "" || auth.signedHeaders == nil || auth.signature == "" {
+		return nil, errors.New("the Authorization header requires Credential, SignedHeaders, and Signature")
+	}
+	return auth, nil
+}
+
+func (sig *AWSSigV4) verify(req *http.Request, body []byte) error { //nolint:cyclop
+	auth, err := parseAWSAuthorization(req.Header.Get("Authorization"))
+	if err != nil {
+		return err
+	}
+	if auth.accessKeyID != sig.AccessKeyID {
+		return fmt.Errorf("the access key id should be %q but %q", sig.AccessKeyID, auth.accessKeyID)
+	}
+	if sig.Region != "" && auth.region != sig.Region {
+		return fmt.Errorf("the region should be %q but %q", sig.Region, auth.region)
+	}
+	if sig.Service != "" && auth.service != sig.Service {
+		return fmt.Errorf("the service should be %q but %q", sig.Service, auth.service)
+	}
+	amzDate := req.Header.Get("X-Amz-Date")
+	if amzDate == "" {
+		return errors.New("the request header X-Amz-Date is required")
+	}
+	if !strings.HasPrefix(amzDate, auth.date) {
+		return fmt.Errorf("the date of the credential scope %s doesn't match X-Amz-Date %s", auth.date, amzDate)
+	}
+	if !slices.Contains(auth.signedHeaders, "host") {
+		return errors.New("the host header should be signed")
+	}
+	scope := strings.Join([]string{auth.date, auth.region, auth.service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		awsSigV4Algorithm,
+		amzDate,
+		scope,
+		hexSHA256([]byte(sig.canonicalRequest(req, body, auth.signedHeaders))),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+sig.SecretAccessKey), auth.date)
+	key = hmacSHA256(key, auth.region)
+	key = hmacSHA256(key, auth.service)
+	key = hmacSHA256(key, "aws4_request")
+	expected := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(auth.signature)) {
+		return errors.New("the signature of AWS Signature Version 4 is wrong")
+	}
+	return nil
+}
+
+func (sig *AWSSigV4) canonicalRequest(req *http.Request, body []byte, signedHeaders []string) string {
+	uri := req.URL.EscapedPath()
+	if req.URL.Opaque != "" {
+		uri = req.URL.Opaque
+	}
+	if uri == "" {
+		uri = "/"
+	}
+	if !sig.DisableURIPathEscaping {
+		uri = awsEscape(uri, false)
+	}
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	queryArr := []string{}
+	for _, k := range keys {
+		values := slices.Clone(query[k])
+		slices.Sort(values)
+		for _, v := range values {
+			queryArr = append(queryArr, awsEscape(k, true)+"="+awsEscape(v, true))
+		}
+	}
+
+	headers := make([]string, len(signedHeaders))
+	for i, name := range signedHeaders {
+		var values []string
+		if name == "host" {
+			host := req.Host
+			if host == "" {
+				host = req.URL.Host
+			}
+			values = []string{host}
+		} else if name == "content-length" && req.Header.Get("Content-Length") == "" {
+			values = []string{strconv.FormatInt(req.ContentLength, 10)}
+		} else {
+			// Values returns the header's own slice, so it is copied not to change the request header
+			values = slices.Clone(req.Header.Values(name))
+		}
+		for j, v := range values {
+			values[j] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[i] = name + ":" + strings.Join(values, ",") + "\n"
+	}
+
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		payloadHash = hexSHA256(body)
+	}
+
+	return strings.Join([]string{
+		req.Method,
+		uri,
+		strings.Join(queryArr, "&"),
+		strings.Join(headers, ""),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+// awsEscape escapes the string in the way of AWS Signature Version 4.
+// Unreserved characters aren't escaped. If encodeSep is false, "/" isn't escaped.
+func awsEscape(s string, encodeSep bool) string {
+	buf := &strings.Builder{}
+	for i := range len(s) {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSep) {
+			buf.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(buf, "%%%02X", c)
+	}
+	return buf.String()
+}
+
+func hexSHA256(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// verifyAuth returns an error if the request doesn't meet the authentication conditions.
+func verifyAuth(req *http.Request, basicAuth *BasicAuth, token string, hmacSig *HMACSignature, awsSig *AWSSigV4) error {
+	if basicAuth != nil {
+		if err := basicAuth.verify(req); err != nil {
+			return err
+		}
+	}
+	if token != "" {
+		if err := verifyBearerToken(req, token); err != nil {
+			return err
+		}
+	}
+	if hmacSig == nil && awsSig == nil {
+		return nil
+	}
+	body, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+	if hmacSig != nil {
+		if err := hmacSig.verify(req, body); err != nil {
+			return err
+		}
+	}
+	if awsSig != nil {
+		if err := awsSig.verify(req, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package flute_test
+
+import (
+	"crypto/sha1"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestTransport_RoundTrip_auth(t *testing.T) { //nolint:funlen
+	hmacSig := &flute.HMACSignature{
+		Key:    []byte("secret"),
+		Header: "X-Hub-Signature-256",
+		Prefix: "sha256=",
+	}
+	awsSig := &flute.AWSSigV4{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "iam",
+	}
+	data := []struct {
+		title    string
+		url      string
+		header   map[string]string
+		body     string
+		user     string
+		password string
+		tester   flute.Tester
+		failures int
+	}{
+		{
+			title:    "basic auth",
+			user:     "foo",
+			password: "bar",
+			tester: flute.Tester{
+				BasicAuth: &flute.BasicAuth{Username: "foo", Password: "bar"},
+			},
+		},
+		{
+			title:    "wrong password",
+			user:     "foo",
+			password: "baz",
+			tester: flute.Tester{
+				BasicAuth: &flute.BasicAuth{Username: "foo", Password: "bar"},
+			},
+			failures: 1,
+		},
+		{
+			title: "bearer token",
+			header: map[string]string{
+				"Authorization": "Bearer XXXXX",
+			},
+			tester: flute.Tester{
+				BearerToken: "XXXXX",
+			},
+		},
+		{
+			title: "wrong bearer token",
+			header: map[string]string{
+				"Authorization": "token XXXXX",
+			},
+			tester: flute.Tester{
+				BearerToken: "XXXXX",
+			},
+			failures: 1,
+		},
+		{
+			title: "hmac",
+			body:  `{"action": "opened"}`,
+			header: map[string]string{
+				"X-Hub-Signature-256": "sha256=0440c0a0f55614d0552fcc335f86f1eb0bb491e46abf30ebf7d776886337c4e8",
+			},
+			tester: flute.Tester{
+				HMAC: hmacSig,
+			},
+		},
+		{
+			title: "wrong hmac",
+			body:  `{"action": "closed"}`,
+			header: map[string]string{
+				"X-Hub-Signature-256": "sha256=0440c0a0f55614d0552fcc335f86f1eb0bb491e46abf30ebf7d776886337c4e8",
+			},
+			tester: flute.Tester{
+				HMAC: hmacSig,
+			},
+			failures: 1,
+		},
+		{
+			title: "hmac with the canonical string",
+			url:   "http://example.com/hooks?id=10",
+			header: map[string]string{
+				"X-Signature": "fsrY+2q0h23Ms4mR/cX7UtLL5k8=",
+			},
+			tester: flute.Tester{
+				HMAC: &flute.HMACSignature{
+					Key:      []byte("secret"),
+					Hash:     sha1.New,
+					Header:   "X-Signature",
+					Encoding: flute.HMACEncodingBase64,
+					CanonicalString: func(req *http.Request, body []byte) string {
+						return req.Method + "\n" + req.URL.RequestURI()
+					},
+				},
+			},
+		},
+		{
+			title: "aws signature v4",
+			url:   "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			header: map[string]string{
+				"Content-Type":  "application/x-www-form-urlencoded; charset=utf-8",
+				"X-Amz-Date":    "20150830T123600Z",
+				"Authorization": "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+			},
+			tester: flute.Tester{
+				AWSSigV4: awsSig,
+			},
+		},
+		{
+			title: "aws signature v4 with the wrong secret",
+			url:   "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			header: map[string]string{
+				"Content-Type":  "application/x-www-form-urlencoded; charset=utf-8",
+				"X-Amz-Date":    "20150830T123600Z",
+				"Authorization": "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d8",
+			},
+			tester: flute.Tester{
+				AWSSigV4: awsSig,
+			},
+			failures: 1,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			u := d.url
+			if u == "" {
+				u = "http://example.com/users"
+			}
+			method := http.MethodGet
+			if d.body != "" {
+				method = http.MethodPost
+			}
+			req, err := http.NewRequestWithContext(t.Context(), method, u, strings.NewReader(d.body))
+			require.NoError(t, err)
+			for k, v := range d.header {
+				req.Header.Set(k, v)
+			}
+			if d.user != "" {
+				req.SetBasicAuth(d.user, d.password)
+			}
+			rep := &recordReporter{}
+			transport := flute.Transport{
+				T:        t,
+				Reporter: rep,
+				Services: []flute.Service{
+					{
+						Endpoint: req.URL.Scheme + "://" + req.URL.Host,
+						Routes: []flute.Route{
+							{
+								Name:   d.title,
+								Tester: d.tester,
+							},
+						},
+					},
+				},
+			}
+			resp, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Len(t, rep.msgs, d.failures, rep.msgs)
+		})
+	}
+}
+
+func TestHMACSignature_Sign(t *testing.T) {
+	sig := &flute.HMACSignature{
+		Key:    []byte("It's a Secret to Everybody"),
+		Prefix: "sha256=",
+	}
+	// https://docs.github.com/en/webhooks/using-webhooks/validating-webhook-deliveries#testing-the-webhook-payload-validation
+	require.Equal(t,
+		"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+		sig.Sign(&http.Request{}, []byte("Hello, World!")))
+}
+
+func TestMatcher_auth(t *testing.T) {
+	transport := flute.Transport{
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Matcher: flute.Matcher{
+							BearerToken: "XXXXX",
+						},
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusOK,
+							},
+						},
+					},
+					{
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusUnauthorized,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for token, status := range map[string]int{"XXXXX": http.StatusOK, "YYYYY": http.StatusUnauthorized} {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, status, resp.StatusCode)
+	}
+}
+
+func TestMatcher_bodyAndHMAC(t *testing.T) {
+	sig := &flute.HMACSignature{
+		Key:    []byte("secret"),
+		Header: "X-Hub-Signature-256",
+		Prefix: "sha256=",
+	}
+	body := `{"action": "opened"}`
+	transport := flute.Transport{
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Matcher: flute.Matcher{
+							BodyJSONString: body,
+							HMAC:           sig,
+						},
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusOK,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://example.com/hooks", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-Hub-Signature-256", sig.Sign(req, []byte(body)))
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestAWSSigV4_headerIsNotChanged(t *testing.T) {
+	transport := flute.Transport{
+		T: t,
+		Services: []flute.Service{
+			{
+				Endpoint: "https://iam.amazonaws.com",
+				Routes: []flute.Route{
+					{
+						Tester: flute.Tester{
+							AWSSigV4: &flute.AWSSigV4{
+								AccessKeyID:     "AKIDEXAMPLE",
+								SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+								Region:          "us-east-1",
+								Service:         "iam",
+							},
+						},
+					},
+				},
+			},
+		},
+		Reporter: &recordReporter{},
+	}
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://iam.amazonaws.com/", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Amz-Date", "20150830T123600Z")
+	req.Header.Set("X-Custom", "a   b")
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=host;x-amz-date;x-custom, Signature=xxx")
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, "a   b", req.Header.Get("X-Custom"))
+}
@@ -0,0 +1,61 @@
+package flute
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// readRequestBody reads the request body and restores it so that it can be read again.
+// If the request has no body, readRequestBody returns nil.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the request body: %w", err)
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// cloneRequest reads and closes the request body and returns the clone of the request with the body.
+// The request isn't changed except for reading the body.
+func cloneRequest(req *http.Request) (*http.Request, []byte, error) {
+	clone := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return clone, nil, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the request body: %w", err)
+	}
+	clone.Body = io.NopCloser(bytes.NewReader(b))
+	return clone, b, nil
+}
+
+// readResponseBody reads the response body and restores it so that it can be read again.
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	if resp.Body == nil {
+		return nil, nil
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response body: %w", err)
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// resetRequestBody sets the body read by readRequestBody to the request again.
+func resetRequestBody(req *http.Request, body []byte) {
+	if body == nil {
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+}
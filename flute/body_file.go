@@ -0,0 +1,58 @@
+package flute
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"strings"
+)
+
+// readBodyFile reads the file and returns the content and the content type.
+// If fsys is nil, the file is read from the local file system.
+// If the file extension is ".gz", the file is decompressed
+// and the content type is got from the extension before ".gz".
+func readBodyFile(fsys fs.FS, name string) ([]byte, string, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if fsys == nil {
+		b, err = os.ReadFile(name)
+	} else {
+		b, err = fs.ReadFile(fsys, name)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("read the body file %s: %w", name, err)
+	}
+	if strings.HasSuffix(name, ".gz") {
+		name = strings.TrimSuffix(name, ".gz")
+		b, err = gunzip(b)
+		if err != nil {
+			return nil, "", fmt.Errorf("decompress the body file %s: %w", name, err)
+		}
+	}
+	return b, mime.TypeByExtension(path.Ext(name)), nil
+}
+
+func gunzip(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// isJSONContentType returns whether the content type is JSON.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
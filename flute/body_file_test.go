@@ -0,0 +1,128 @@
+package flute_test
+
+import (
+	"embed"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+//go:embed testdata
+var testdata embed.FS
+
+func TestTransport_RoundTrip_bodyFile(t *testing.T) { //nolint:funlen
+	data := []struct {
+		title       string
+		body        string
+		route       flute.Route
+		contentType string
+		exp         string
+		failures    int
+	}{
+		{
+			title: "gzip compressed JSON from embed.FS",
+			body:  `{"name": "foo", "email": "foo@example.com"}`,
+			route: flute.Route{
+				Matcher: flute.Matcher{
+					BodyFile: "testdata/new_user.json",
+					FS:       testdata,
+				},
+				Tester: flute.Tester{
+					BodyFile: "testdata/new_user.json",
+					FS:       testdata,
+				},
+				Response: flute.Response{
+					BodyFile: "testdata/user.json.gz",
+					FS:       testdata,
+				},
+			},
+			contentType: "application/json",
+			exp:         `{"id": 10, "name": "foo", "email": "foo@example.com"}`,
+		},
+		{
+			title: "text from the local file system",
+			body:  "hello",
+			route: flute.Route{
+				Tester: flute.Tester{
+					BodyFile: "testdata/hello.txt",
+				},
+				Response: flute.Response{
+					BodyFile: "testdata/hello.txt",
+				},
+			},
+			contentType: "text/plain; charset=utf-8",
+			exp:         "hello",
+		},
+		{
+			title: "the body is read by another tester before",
+			body:  "hello",
+			route: flute.Route{
+				Tester: flute.Tester{
+					BodyString: "hello",
+					BodyFile:   "testdata/hello.txt",
+				},
+				Response: flute.Response{
+					BodyFile: "testdata/hello.txt",
+				},
+			},
+			contentType: "text/plain; charset=utf-8",
+			exp:         "hello",
+		},
+		{
+			title: "Content-Type isn't overwritten",
+			body:  "bye",
+			route: flute.Route{
+				Tester: flute.Tester{
+					BodyFile: "testdata/hello.txt",
+				},
+				Response: flute.Response{
+					Base: http.Response{
+						Header: http.Header{
+							"Content-Type": []string{"text/html"},
+						},
+					},
+					BodyFile: "testdata/hello.txt",
+				},
+			},
+			contentType: "text/html",
+			exp:         "hello",
+			failures:    1,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			rep := &recordReporter{}
+			transport := flute.Transport{
+				T:        t,
+				Reporter: rep,
+				Services: []flute.Service{
+					{
+						Endpoint: "http://example.com",
+						Routes:   []flute.Route{d.route},
+					},
+				},
+			}
+			resp, err := transport.RoundTrip(&http.Request{
+				URL: &url.URL{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   "/users",
+				},
+				Method: http.MethodPost,
+				Body:   io.NopCloser(strings.NewReader(d.body)),
+			})
+			require.NoError(t, err)
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, d.exp, string(b))
+			require.Equal(t, d.contentType, resp.Header.Get("Content-Type"))
+			require.Len(t, rep.msgs, d.failures)
+		})
+	}
+}
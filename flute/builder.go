@@ -0,0 +1,361 @@
+package flute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type (
+	// ServiceBuilder builds a Service with a fluent API.
+	//
+	//	service, err := flute.NewService("http://example.com").
+	//		Post("/users").
+	//		ExpectJSON(map[string]any{"name": "foo"}).
+	//		RespondJSON(http.StatusCreated, map[string]any{"id": 10, "name": "foo"}).
+	//		Build()
+	//
+	// The built Service is the same structure as the struct form,
+	// so routes can be added with AddRoute and the Service can be changed after Build.
+	ServiceBuilder struct {
+		service Service
+		routes  []*RouteBuilder
+	}
+
+	// RouteBuilder builds a Route of the ServiceBuilder.
+	// Route methods of the ServiceBuilder such as Get, Post and Build can be called from RouteBuilder
+	// to add the next route or build the service.
+	RouteBuilder struct {
+		service *ServiceBuilder
+		route   Route
+		errs    []error
+		// defaultName is whether the route name is the default name
+		defaultName bool
+	}
+)
+
+// NewService returns a ServiceBuilder of the endpoint such as "http://example.com".
+func NewService(endpoint string) *ServiceBuilder {
+	return &ServiceBuilder{
+		service: Service{
+			Endpoint: endpoint,
+		},
+	}
+}
+
+// OpenAPI sets Service.OpenAPI.
+func (builder *ServiceBuilder) OpenAPI(oa *OpenAPI) *ServiceBuilder {
+	builder.service.OpenAPI = oa
+	return builder
+}
+
+// RateLimiter sets Service.RateLimiter.
+func (builder *ServiceBuilder) RateLimiter(limiter RateLimiter) *ServiceBuilder {
+	builder.service.RateLimiter = limiter
+	return builder
+}
+
+// AddRoute adds the route as it is.
+func (builder *ServiceBuilder) AddRoute(route Route) *ServiceBuilder {
+	builder.routes = append(builder.routes, &RouteBuilder{
+		service: builder,
+		route:   route,
+	})
+	return builder
+}
+
+// Route adds a route which matches the request method and path and returns the RouteBuilder.
+// If the path includes "{...}" such as "/users/{id}", the path is used as Matcher.PathTemplate.
+// The route name defaults to the method and path such as "POST /users".
+// If the default name is used already, for example by a route which differs only by the query,
+// Build numbers the name such as "GET /users (2)".
+func (builder *ServiceBuilder) Route(method, path string) *RouteBuilder {
+	rb := &RouteBuilder{
+		service:     builder,
+		defaultName: true,
+		route: Route{
+			Name: method + " " + path,
+			Matcher: Matcher{
+				Method: method,
+			},
+		},
+	}
+	if strings.Contains(path, "{") {
+		rb.route.Matcher.PathTemplate = path
+	} else {
+		rb.route.Matcher.Path = path
+	}
+	if !strings.HasPrefix(path, "/") {
+		rb.errs = append(rb.errs, fmt.Errorf("the path must start with \"/\": %s", path))
+	}
+	builder.routes = append(builder.routes, rb)
+	return rb
+}
+
+// Get adds a route of the GET method.
+func (builder *ServiceBuilder) Get(path string) *RouteBuilder {
+	return builder.Route(http.MethodGet, path)
+}
+
+// Post adds a route of the POST method.
+func (builder *ServiceBuilder) Post(path string) *RouteBuilder {
+	return builder.Route(http.MethodPost, path)
+}
+
+// Put adds a route of the PUT method.
+func (builder *ServiceBuilder) Put(path string) *RouteBuilder {
+	return builder.Route(http.MethodPut, path)
+}
+
+// Patch adds a route of the PATCH method.
+func (builder *ServiceBuilder) Patch(path string) *RouteBuilder {
+	return builder.Route(http.MethodPatch, path)
+}
+
+// Delete adds a route of the DELETE method.
+func (builder *ServiceBuilder) Delete(path string) *RouteBuilder {
+	return builder.Route(http.MethodDelete, path)
+}
+
+// Build validates the service and routes and returns the Service.
+// In addition to the mistakes which Service.Validate reports, Build reports mistakes specific to the builder
+// such as a path which doesn't start with "/".
+// All validation errors are joined.
+func (builder *ServiceBuilder) Build() (Service, error) {
+	service := builder.service
+	service.Routes = make([]Route, len(builder.routes))
+	names := builder.defaultNames()
+	var errs []error
+	for i, rb := range builder.routes {
+		service.Routes[i] = rb.route
+		service.Routes[i].Name = names[i]
+		for _, err := range rb.validate() {
+			errs = append(errs, fmt.Errorf("%s: %w", routeLabel(rb.route, i), err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		errs = []error{fmt.Errorf("service %s: %w", service.Endpoint, err)}
+	}
+	if err := service.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Service{}, fmt.Errorf("build the service: %w", err)
+	}
+	return service, nil
+}
+
+// defaultNames returns the route names where the duplicated default names are numbered.
+// The names which are set explicitly are kept as they are, so that Service.Validate reports their duplication.
+func (builder *ServiceBuilder) defaultNames() []string {
+	names := make([]string, len(builder.routes))
+	used := make(map[string]struct{}, len(builder.routes))
+	for i, rb := range builder.routes {
+		names[i] = rb.route.Name
+		if !rb.defaultName {
+			used[rb.route.Name] = struct{}{}
+		}
+	}
+	for i, rb := range builder.routes {
+		if !rb.defaultName {
+			continue
+		}
+		name := rb.route.Name
+		for n := 2; ; n++ {
+			if _, ok := used[name]; !ok {
+				break
+			}
+			name = fmt.Sprintf("%s (%d)", rb.route.Name, n)
+		}
+		used[name] = struct{}{}
+		names[i] = name
+	}
+	return names
+}
+
+// validate returns the mistakes which Service.Validate doesn't report.
+func (rb *RouteBuilder) validate() []error {
+	errs := rb.errs
+	tester := rb.route.Tester
+	n := 0
+	for _, set := range []bool{tester.BodyString != "", tester.BodyJSON != nil, tester.BodyJSONString != ""} {
+		if set {
+			n++
+		}
+	}
+	if n > 1 {
+		errs = append(errs, errors.New("only one of the expected request bodies can be set"))
+	}
+	resp := rb.route.Response
+	if resp.BodyJSON != nil {
+		if _, err := json.Marshal(resp.BodyJSON); err != nil {
+			errs = append(errs, fmt.Errorf("marshal the response body to JSON: %w", err))
+		}
+	}
+	if code := resp.Base.StatusCode; code != 0 && (code < 100 || code > 999) {
+		errs = append(errs, fmt.Errorf("invalid status code: %d", code))
+	}
+	return errs
+}
+
+// Name sets the route name.
+func (rb *RouteBuilder) Name(name string) *RouteBuilder {
+	rb.route.Name = name
+	rb.defaultName = false
+	return rb
+}
+
+// MatchHeader adds the request header to Matcher.PartOfHeader.
+// If values are empty, the route matches the request which has the header.
+func (rb *RouteBuilder) MatchHeader(key string, values ...string) *RouteBuilder {
+	rb.route.Matcher.PartOfHeader = addHeader(rb.route.Matcher.PartOfHeader, key, values)
+	return rb
+}
+
+// MatchQuery adds the query parameter to Matcher.PartOfQuery.
+func (rb *RouteBuilder) MatchQuery(key string, values ...string) *RouteBuilder {
+	if rb.route.Matcher.PartOfQuery == nil {
+		rb.route.Matcher.PartOfQuery = url.Values{}
+	}
+	rb.route.Matcher.PartOfQuery[key] = values
+	return rb
+}
+
+// Match sets Matcher.Match.
+func (rb *RouteBuilder) Match(fn func(req *http.Request) (bool, error)) *RouteBuilder {
+	rb.route.Matcher.Match = fn
+	return rb
+}
+
+// ExpectHeader adds the request header to Tester.PartOfHeader.
+// If values are empty, the test checks whether the request has the header.
+func (rb *RouteBuilder) ExpectHeader(key string, values ...string) *RouteBuilder {
+	rb.route.Tester.PartOfHeader = addHeader(rb.route.Tester.PartOfHeader, key, values)
+	return rb
+}
+
+// ExpectQuery adds the query parameter to Tester.PartOfQuery.
+func (rb *RouteBuilder) ExpectQuery(key string, values ...string) *RouteBuilder {
+	if rb.route.Tester.PartOfQuery == nil {
+		rb.route.Tester.PartOfQuery = url.Values{}
+	}
+	rb.route.Tester.PartOfQuery[key] = values
+	return rb
+}
+
+// ExpectJSON sets Tester.BodyJSON.
+func (rb *RouteBuilder) ExpectJSON(body any) *RouteBuilder {
+	rb.route.Tester.BodyJSON = body
+	return rb
+}
+
+// ExpectJSONString sets Tester.BodyJSONString.
+func (rb *RouteBuilder) ExpectJSONString(body string) *RouteBuilder {
+	rb.route.Tester.BodyJSONString = body
+	return rb
+}
+
+// ExpectString sets Tester.BodyString.
+func (rb *RouteBuilder) ExpectString(body string) *RouteBuilder {
+	rb.route.Tester.BodyString = body
+	return rb
+}
+
+// Test sets Tester.Test.
+func (rb *RouteBuilder) Test(fn func(testing.TB, *http.Request, Service, Route)) *RouteBuilder {
+	rb.route.Tester.Test = fn
+	return rb
+}
+
+// Respond sets the response status code.
+func (rb *RouteBuilder) Respond(statusCode int) *RouteBuilder {
+	rb.route.Response.Base.StatusCode = statusCode
+	return rb
+}
+
+// RespondJSON sets the response status code and Response.BodyJSON.
+func (rb *RouteBuilder) RespondJSON(statusCode int, body any) *RouteBuilder {
+	rb.route.Response.Base.StatusCode = statusCode
+	rb.route.Response.BodyJSON = body
+	return rb
+}
+
+// RespondString sets the response status code and Response.BodyString.
+func (rb *RouteBuilder) RespondString(statusCode int, body string) *RouteBuilder {
+	rb.route.Response.Base.StatusCode = statusCode
+	rb.route.Response.BodyString = body
+	return rb
+}
+
+// RespondHeader adds the response header.
+func (rb *RouteBuilder) RespondHeader(key string, values ...string) *RouteBuilder {
+	rb.route.Response.Base.Header = addHeader(rb.route.Response.Base.Header, key, values)
+	return rb
+}
+
+// RespondFunc sets Response.Response.
+func (rb *RouteBuilder) RespondFunc(fn func(req *http.Request) (*http.Response, error)) *RouteBuilder {
+	rb.route.Response.Response = fn
+	return rb
+}
+
+// Route adds the next route. See ServiceBuilder.Route.
+func (rb *RouteBuilder) Route(method, path string) *RouteBuilder {
+	return rb.service.Route(method, path)
+}
+
+// Get adds the next route of the GET method.
+func (rb *RouteBuilder) Get(path string) *RouteBuilder {
+	return rb.service.Get(path)
+}
+
+// Post adds the next route of the POST method.
+func (rb *RouteBuilder) Post(path string) *RouteBuilder {
+	return rb.service.Post(path)
+}
+
+// Put adds the next route of the PUT method.
+func (rb *RouteBuilder) Put(path string) *RouteBuilder {
+	return rb.service.Put(path)
+}
+
+// Patch adds the next route of the PATCH method.
+func (rb *RouteBuilder) Patch(path string) *RouteBuilder {
+	return rb.service.Patch(path)
+}
+
+// Delete adds the next route of the DELETE method.
+func (rb *RouteBuilder) Delete(path string) *RouteBuilder {
+	return rb.service.Delete(path)
+}
+
+// AddRoute adds the next route as it is.
+func (rb *RouteBuilder) AddRoute(route Route) *ServiceBuilder {
+	return rb.service.AddRoute(route)
+}
+
+// Build builds the service. See ServiceBuilder.Build.
+func (rb *RouteBuilder) Build() (Service, error) {
+	return rb.service.Build()
+}
+
+// addHeader adds the header values to the header.
+// If values are empty, the key is added with the nil value.
+func addHeader(header http.Header, key string, values []string) http.Header {
+	if header == nil {
+		header = http.Header{}
+	}
+	key = http.CanonicalHeaderKey(key)
+	if len(values) == 0 {
+		if _, ok := header[key]; !ok {
+			header[key] = nil
+		}
+		return header
+	}
+	header[key] = append(header[key], values...)
+	return header
+}
@@ -0,0 +1,190 @@
+package flute_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestServiceBuilder_Build(t *testing.T) { //nolint:funlen
+	data := []struct {
+		title   string
+		builder interface {
+			Build() (flute.Service, error)
+		}
+		exp   flute.Service
+		isErr bool
+	}{
+		{
+			title: "normal",
+			builder: flute.NewService("http://example.com").
+				Post("/users").
+				Name("create a user").
+				ExpectHeader("authorization", "token XXXXX").
+				ExpectJSON(map[string]any{"name": "foo"}).
+				RespondJSON(http.StatusCreated, map[string]any{"id": 10}).
+				Get("/users/{id}").
+				MatchQuery("fields", "name").
+				RespondString(http.StatusOK, "foo").
+				AddRoute(flute.Route{
+					Name: "delete a user",
+				}),
+			exp: flute.Service{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Name: "create a user",
+						Matcher: flute.Matcher{
+							Method: http.MethodPost,
+							Path:   "/users",
+						},
+						Tester: flute.Tester{
+							PartOfHeader: http.Header{
+								"Authorization": []string{"token XXXXX"},
+							},
+							BodyJSON: map[string]any{"name": "foo"},
+						},
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusCreated,
+							},
+							BodyJSON: map[string]any{"id": 10},
+						},
+					},
+					{
+						Name: "GET /users/{id}",
+						Matcher: flute.Matcher{
+							Method:       http.MethodGet,
+							PathTemplate: "/users/{id}",
+							PartOfQuery: url.Values{
+								"fields": []string{"name"},
+							},
+						},
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusOK,
+							},
+							BodyString: "foo",
+						},
+					},
+					{
+						Name: "delete a user",
+					},
+				},
+			},
+		},
+		{
+			title:   "invalid endpoint",
+			builder: flute.NewService("example.com"),
+			isErr:   true,
+		},
+		{
+			title:   "invalid path",
+			builder: flute.NewService("http://example.com").Get("users").Respond(http.StatusOK),
+			isErr:   true,
+		},
+		{
+			title: "multiple request bodies",
+			builder: flute.NewService("http://example.com").
+				Post("/users").
+				ExpectJSON(map[string]any{"name": "foo"}).
+				ExpectString("foo"),
+			isErr: true,
+		},
+		{
+			title:   "invalid JSON",
+			builder: flute.NewService("http://example.com").Post("/users").ExpectJSONString("{"),
+			isErr:   true,
+		},
+		{
+			title:   "response body can't be marshaled",
+			builder: flute.NewService("http://example.com").Get("/users").RespondJSON(http.StatusOK, func() {}),
+			isErr:   true,
+		},
+		{
+			title: "duplicated route names",
+			builder: flute.NewService("http://example.com").
+				Get("/users").Name("list users").
+				Post("/users").Name("list users"),
+			isErr: true,
+		},
+		{
+			title: "shadowed route",
+			builder: flute.NewService("http://example.com").
+				Get("/users").Name("list users").
+				Get("/users").Name("list users of page 2").MatchQuery("page", "2"),
+			isErr: true,
+		},
+		{
+			title: "routes which differ only by the query",
+			builder: flute.NewService("http://example.com").
+				Get("/users").MatchQuery("page", "1").
+				Get("/users").MatchQuery("page", "2").
+				Get("/users").Name("GET /users (3)").MatchQuery("page", "3"),
+			exp: flute.Service{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Name: "GET /users",
+						Matcher: flute.Matcher{
+							Method: http.MethodGet,
+							Path:   "/users",
+							PartOfQuery: url.Values{
+								"page": []string{"1"},
+							},
+						},
+					},
+					{
+						Name: "GET /users (2)",
+						Matcher: flute.Matcher{
+							Method: http.MethodGet,
+							Path:   "/users",
+							PartOfQuery: url.Values{
+								"page": []string{"2"},
+							},
+						},
+					},
+					{
+						Name: "GET /users (3)",
+						Matcher: flute.Matcher{
+							Method: http.MethodGet,
+							Path:   "/users",
+							PartOfQuery: url.Values{
+								"page": []string{"3"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			title: "multiple response bodies",
+			builder: flute.NewService("http://example.com").AddRoute(flute.Route{
+				Response: flute.Response{
+					BodyString: "foo",
+					BodyFile:   "user.json",
+				},
+			}),
+			isErr: true,
+		},
+		{
+			title:   "invalid status code",
+			builder: flute.NewService("http://example.com").Get("/users").Respond(20),
+			isErr:   true,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			service, err := d.builder.Build()
+			if d.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, d.exp, service)
+		})
+	}
+}
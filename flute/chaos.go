@@ -0,0 +1,168 @@
+package flute
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ErrChaos is the error which Chaos injects into RoundTrip.
+var ErrChaos = errors.New("flute: error injected by chaos")
+
+// Chaos injects failures into the transport randomly at the configured rates.
+// Each rate is the probability between 0 and 1.
+// The failures are reproducible with the seed, which is logged when the test fails.
+// Chaos must not be copied after first use.
+type Chaos struct {
+	// Seed is the seed of the random number generator.
+	// If Seed is zero, the seed is chosen randomly.
+	Seed uint64
+	// ErrorRate is the rate of RoundTrip returning ErrChaos.
+	ErrorRate float64
+	// ServerErrorRate is the rate of returning a 5xx response instead of the route's response.
+	ServerErrorRate float64
+	// ServerErrorStatusCodes are the status codes of the 5xx response.
+	// The default is 500, 502, 503, and 504.
+	ServerErrorStatusCodes []int
+	// DelayRate is the rate of delaying the response.
+	DelayRate float64
+	// MaxDelay is the maximum delay. The delay is chosen randomly from zero to MaxDelay.
+	MaxDelay time.Duration
+	// TruncateRate is the rate of truncating the response body.
+	// Reading the truncated body fails with io.ErrUnexpectedEOF.
+	TruncateRate float64
+
+	once       sync.Once
+	mu         sync.Mutex
+	seed       uint64
+	rand       *rand.Rand
+	registered map[testing.TB]struct{}
+}
+
+var defaultChaosServerErrorStatusCodes = []int{ //nolint:gochecknoglobals
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+func (chaos *Chaos) init() {
+	chaos.once.Do(func() {
+		chaos.seed = chaos.Seed
+		if chaos.seed == 0 {
+			chaos.seed = rand.Uint64() //nolint:gosec
+		}
+		chaos.rand = rand.New(rand.NewPCG(chaos.seed, chaos.seed)) //nolint:gosec
+		chaos.registered = map[testing.TB]struct{}{}
+	})
+}
+
+// CurrentSeed returns the seed of the random number generator.
+// Set the seed to Seed to reproduce the failures.
+func (chaos *Chaos) CurrentSeed() uint64 {
+	chaos.init()
+	return chaos.seed
+}
+
+// register logs the seed when the test fails.
+func (chaos *Chaos) register(t testing.TB) {
+	chaos.init()
+	chaos.mu.Lock()
+	defer chaos.mu.Unlock()
+	if _, ok := chaos.registered[t]; ok {
+		return
+	}
+	chaos.registered[t] = struct{}{}
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("flute chaos seed: %d", chaos.seed)
+		}
+	})
+}
+
+// chaosDecision is the failures injected into a request.
+type chaosDecision struct {
+	err         bool
+	delay       time.Duration
+	serverError int
+	truncate    float64
+}
+
+// decide decides the failures of the request.
+// Random numbers are drawn in the fixed order to reproduce the failures with the seed.
+func (chaos *Chaos) decide() chaosDecision {
+	chaos.init()
+	chaos.mu.Lock()
+	defer chaos.mu.Unlock()
+	d := chaosDecision{}
+	d.err = chaos.rand.Float64() < chaos.ErrorRate
+	if chaos.rand.Float64() < chaos.DelayRate && chaos.MaxDelay > 0 {
+		d.delay = time.Duration(chaos.rand.Int64N(int64(chaos.MaxDelay) + 1))
+	}
+	codes := chaos.ServerErrorStatusCodes
+	if len(codes) == 0 {
+		codes = defaultChaosServerErrorStatusCodes
+	}
+	if chaos.rand.Float64() < chaos.ServerErrorRate {
+		d.serverError = codes[chaos.rand.IntN(len(codes))]
+	}
+	if chaos.rand.Float64() < chaos.TruncateRate {
+		d.truncate = chaos.rand.Float64()
+	}
+	return d
+}
+
+// before injects the delay and the error before the request is handled.
+func (d chaosDecision) before(req *http.Request) error {
+	if d.delay > 0 {
+		timer := time.NewTimer(d.delay)
+		defer timer.Stop()
+		select {
+		case <-req.Context().Done():
+			return req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	if d.err {
+		return ErrChaos
+	}
+	return nil
+}
+
+// after injects the server error and the truncated body into the response.
+func (d chaosDecision) after(req *http.Request, resp *http.Response) (*http.Response, error) {
+	if d.serverError != 0 {
+		if resp.Body != nil {
+			resp.Body.Close()
+		}
+		body := fmt.Sprintf(`{"message": "%s"}`, http.StatusText(d.serverError))
+		return newResponse(req, d.serverError, nil, body, "application/json"), nil
+	}
+	if d.truncate == 0 || resp.Body == nil || isStreamResponse(resp) {
+		return resp, nil
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read the response body: %w", err)
+	}
+	resp.Body = io.NopCloser(io.MultiReader(
+		bytes.NewReader(b[:int(float64(len(b))*d.truncate)]),
+		errReader{err: io.ErrUnexpectedEOF},
+	))
+	return resp, nil
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
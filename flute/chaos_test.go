@@ -0,0 +1,168 @@
+package flute_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func newChaosTransport(t *testing.T, chaos *flute.Chaos) flute.Transport {
+	t.Helper()
+	return flute.Transport{
+		T:     t,
+		Chaos: chaos,
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusOK,
+							},
+							BodyString: `{"id": 10, "name": "foo"}`,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// chaosOutcome returns the outcome of the request as a string.
+func chaosOutcome(t *testing.T, transport flute.Transport) string {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Sprintf("%d truncated: %s", resp.StatusCode, string(b))
+	}
+	return fmt.Sprintf("%d: %s", resp.StatusCode, string(b))
+}
+
+func TestChaos(t *testing.T) {
+	data := []struct {
+		title string
+		chaos *flute.Chaos
+		exp   string
+	}{
+		{
+			title: "error",
+			chaos: &flute.Chaos{ErrorRate: 1},
+			exp:   "error: " + flute.ErrChaos.Error(),
+		},
+		{
+			title: "server error",
+			chaos: &flute.Chaos{
+				ServerErrorRate:        1,
+				ServerErrorStatusCodes: []int{http.StatusServiceUnavailable},
+			},
+			exp: `503: {"message": "Service Unavailable"}`,
+		},
+		{
+			title: "no failure",
+			chaos: &flute.Chaos{},
+			exp:   `200: {"id": 10, "name": "foo"}`,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			require.Equal(t, d.exp, chaosOutcome(t, newChaosTransport(t, d.chaos)))
+		})
+	}
+}
+
+func TestChaos_truncate(t *testing.T) {
+	transport := newChaosTransport(t, &flute.Chaos{TruncateRate: 1})
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	_, err = io.ReadAll(resp.Body)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestChaos_seed(t *testing.T) {
+	outcomes := make([][]string, 2)
+	for i := range outcomes {
+		transport := newChaosTransport(t, &flute.Chaos{
+			Seed:            42,
+			ErrorRate:       0.2,
+			ServerErrorRate: 0.2,
+			TruncateRate:    0.2,
+		})
+		for range 50 {
+			outcomes[i] = append(outcomes[i], chaosOutcome(t, transport))
+		}
+	}
+	require.Equal(t, outcomes[0], outcomes[1])
+	require.Contains(t, outcomes[0], `200: {"id": 10, "name": "foo"}`)
+	require.Contains(t, outcomes[0], "error: "+flute.ErrChaos.Error())
+
+	require.NotZero(t, (&flute.Chaos{}).CurrentSeed())
+	require.Equal(t, uint64(42), (&flute.Chaos{Seed: 42}).CurrentSeed())
+}
+
+func TestChaos_recorder(t *testing.T) {
+	data := []struct {
+		title string
+		chaos *flute.Chaos
+		exp   string
+		// replay is the outcome of the request to the services read from the recorded HAR
+		replay string
+	}{
+		{
+			title: "error",
+			chaos: &flute.Chaos{ErrorRate: 1},
+			exp:   "error: " + flute.ErrChaos.Error(),
+		},
+		{
+			title: "server error",
+			chaos: &flute.Chaos{
+				ServerErrorRate:        1,
+				ServerErrorStatusCodes: []int{http.StatusServiceUnavailable},
+			},
+			exp: `503: {"message": "Service Unavailable"}`,
+		},
+		{
+			title: "truncate",
+			chaos: &flute.Chaos{Seed: 1, TruncateRate: 1},
+			exp:   `200 truncated: {"id": 10, `,
+			// the truncated body is recorded
+			replay: `200: {"id": 10, `,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			recorder := &flute.Recorder{}
+			transport := newChaosTransport(t, d.chaos)
+			transport.Recorder = recorder
+			require.Equal(t, d.exp, chaosOutcome(t, transport))
+			// the recorded response is what the client received
+			buf := &bytes.Buffer{}
+			require.NoError(t, recorder.WriteHAR(buf))
+			services, err := flute.ReadHAR(buf)
+			require.NoError(t, err)
+			replay := d.replay
+			if replay == "" {
+				replay = d.exp
+			}
+			require.Equal(t, replay, chaosOutcome(t, flute.Transport{
+				T:        t,
+				Services: services,
+			}))
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package flute
+
+import "time"
+
+type (
+	// Clock returns the current time.
+	// Clock is injected to test time-dependent behavior deterministically.
+	Clock interface {
+		Now() time.Time
+	}
+
+	realClock struct{}
+)
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// getClock returns the clock. If clock is nil, the real clock is returned.
+func getClock(clock Clock) Clock {
+	if clock == nil {
+		return realClock{}
+	}
+	return clock
+}
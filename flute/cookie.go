@@ -0,0 +1,102 @@
+package flute
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// CookieChecker checks that the client sends back the cookies which the transport set.
+// CookieChecker stores cookies of Set-Cookie response headers like a cookie jar,
+// and checks that later requests have the stored cookies.
+// The zero value is ready to use.
+type CookieChecker struct {
+	once sync.Once
+	jar  *cookiejar.Jar
+}
+
+func (checker *CookieChecker) init() {
+	checker.once.Do(func() {
+		// cookiejar.New never returns an error
+		checker.jar, _ = cookiejar.New(nil)
+	})
+}
+
+// missingCookies returns the names of the stored cookies which the request doesn't send back.
+func (checker *CookieChecker) missingCookies(req *http.Request) []string {
+	checker.init()
+	var missing []string
+	for _, expected := range checker.jar.Cookies(req.URL) {
+		c, err := req.Cookie(expected.Name)
+		if err != nil || c.Value != expected.Value {
+			missing = append(missing, expected.Name)
+		}
+	}
+	return missing
+}
+
+// store stores the cookies of the response.
+func (checker *CookieChecker) store(req *http.Request, resp *http.Response) {
+	checker.init()
+	if cookies := resp.Cookies(); len(cookies) != 0 {
+		checker.jar.SetCookies(req.URL, cookies)
+	}
+}
+
+func (checker *CookieChecker) check(req *http.Request) error {
+	if missing := checker.missingCookies(req); len(missing) != 0 {
+		return fmt.Errorf("the request should send back the following cookies: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func cookieValues(req *http.Request) map[string][]string {
+	m := map[string][]string{}
+	for _, c := range req.Cookies() {
+		m[c.Name] = append(m[c.Name], c.Value)
+	}
+	return m
+}
+
+func matchPartOfCookie(req *http.Request, matcher Matcher) (bool, error) {
+	if matcher.PartOfCookie == nil {
+		return true, nil
+	}
+	cookies := cookieValues(req)
+	for k, v := range matcher.PartOfCookie {
+		a, ok := cookies[k]
+		if !ok {
+			return false, nil
+		}
+		if v != nil && !slices.Equal(a, v) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func testPartOfCookie(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
+	if route.Tester.PartOfCookie == nil {
+		return
+	}
+
+	cookies := cookieValues(req)
+	for k, v := range route.Tester.PartOfCookie {
+		a, ok := cookies[k]
+		if !ok {
+			rep.Fail(
+				t, makeMsg(
+					"the following request cookie is required: "+k, service.Endpoint, route.Name))
+			return
+		}
+		if v != nil {
+			rep.Equal(
+				t, v, a,
+				makeMsg(fmt.Sprintf(`the request cookie "%s" should match`, k), service.Endpoint, route.Name))
+		}
+	}
+}
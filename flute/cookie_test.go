@@ -0,0 +1,146 @@
+package flute_test
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func newCookieTransport(t *testing.T, rep flute.Reporter) flute.Transport {
+	t.Helper()
+	return flute.Transport{
+		T:             t,
+		Reporter:      rep,
+		CookieChecker: &flute.CookieChecker{},
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Name: "login",
+						Matcher: flute.Matcher{
+							Path: "/login",
+						},
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusOK,
+							},
+							Cookies: []*http.Cookie{
+								{
+									Name:     "session",
+									Value:    "XXXXX",
+									Path:     "/",
+									HttpOnly: true,
+								},
+							},
+						},
+					},
+					{
+						Name: "get a user",
+						Matcher: flute.Matcher{
+							Path: "/users/10",
+						},
+						Tester: flute.Tester{
+							PartOfCookie: map[string][]string{
+								"session": {"XXXXX"},
+							},
+						},
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusOK,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTransport_RoundTrip_cookie(t *testing.T) {
+	data := []struct {
+		title    string
+		jar      bool
+		failures int
+	}{
+		{
+			title: "the client sends back the cookie",
+			jar:   true,
+		},
+		{
+			title:    "the client doesn't send back the cookie",
+			failures: 2,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			rep := &recordReporter{}
+			client := &http.Client{
+				Transport: newCookieTransport(t, rep),
+			}
+			if d.jar {
+				jar, err := cookiejar.New(nil)
+				require.NoError(t, err)
+				client.Jar = jar
+			}
+			for _, path := range []string{"/login", "/users/10"} {
+				req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com"+path, nil)
+				require.NoError(t, err)
+				resp, err := client.Do(req)
+				require.NoError(t, err)
+				resp.Body.Close()
+				if path == "/login" {
+					require.Equal(t, []string{"session=XXXXX; Path=/; HttpOnly"}, resp.Header.Values("Set-Cookie"))
+				}
+			}
+			require.Len(t, rep.msgs, d.failures, rep.msgs)
+		})
+	}
+}
+
+func TestMatcher_PartOfCookie(t *testing.T) {
+	transport := flute.Transport{
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Matcher: flute.Matcher{
+							PartOfCookie: map[string][]string{
+								"session": nil,
+							},
+						},
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusOK,
+							},
+						},
+					},
+					{
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusUnauthorized,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, cookie := range []*http.Cookie{{Name: "session", Value: "foo"}, {Name: "other", Value: "foo"}} {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users", nil)
+		require.NoError(t, err)
+		req.AddCookie(cookie)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		if cookie.Name == "session" {
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			continue
+		}
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+}
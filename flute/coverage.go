@@ -0,0 +1,263 @@
+package flute
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+	"weak"
+)
+
+// DefaultCoverage is the Coverage which transports record to if Transport.Coverage is nil.
+// Write the report at the end of the test run, for example from TestMain.
+var DefaultCoverage = &Coverage{} //nolint:gochecknoglobals
+
+type (
+	// Coverage records which routes were hit and how many times across transports.
+	// The routes of the transport are registered when the transport handles the first request.
+	// To report routes of transports which handle no request, call Register.
+	// A route is identified by the endpoint and the route name, so the same route of derived transports is counted once.
+	// Unnamed routes are identified by the matcher.
+	// The zero value is ready to use.
+	Coverage struct {
+		mu      sync.Mutex
+		routes  []*RouteCoverage
+		indexes map[routeKey]int
+		// registered caches the route coverages of the registered services by the first service,
+		// so that the services of the same transport are registered only once.
+		// The key is a weak pointer, so the cache doesn't keep the services alive.
+		registered map[weak.Pointer[Service]]*registration
+	}
+
+	// RouteCoverage is the coverage of a route.
+	RouteCoverage struct {
+		Endpoint string `json:"endpoint"`
+		// Name is the route name.
+		Name string `json:"name,omitempty"`
+		// Index is the index of the route in the service where the route was registered first.
+		Index int `json:"index"`
+		// Hits is the number of requests which the route handled.
+		Hits int `json:"hits"`
+	}
+
+	// CoverageReport is the coverage report.
+	CoverageReport struct {
+		Total  int              `json:"total"`
+		Hit    int              `json:"hit"`
+		Routes []*RouteCoverage `json:"routes"`
+	}
+
+	routeKey struct {
+		endpoint string
+		name     string
+		// matcher is the matcher of the unnamed route
+		matcher string
+	}
+
+	// registration is the route coverages of the registered services.
+	// It doesn't refer to the services, so that they can be garbage collected.
+	registration struct {
+		services []registeredService
+		// routes are the route coverages of each service's routes
+		routes [][]*RouteCoverage
+	}
+
+	// registeredService identifies the registered service without referring to it.
+	registeredService struct {
+		endpoint string
+		routes   int
+		// firstRoute is the first route of the service
+		firstRoute weak.Pointer[Route]
+	}
+)
+
+// Register registers the routes of the services.
+func (coverage *Coverage) Register(services ...Service) {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+	coverage.registerServices(services)
+}
+
+// registerServices registers the routes of the services and returns their route coverages.
+// If the services were registered, the cached route coverages are returned.
+// coverage.mu must be locked.
+func (coverage *Coverage) registerServices(services []Service) *registration {
+	if len(services) == 0 {
+		return &registration{}
+	}
+	key := weak.Make(&services[0])
+	reg, ok := coverage.registered[key]
+	if ok && reg.isFor(services) {
+		return reg
+	}
+	reg = &registration{
+		services: make([]registeredService, len(services)),
+		routes:   make([][]*RouteCoverage, len(services)),
+	}
+	for i, service := range services {
+		reg.services[i] = registeredService{
+			endpoint: service.Endpoint,
+			routes:   len(service.Routes),
+		}
+		if len(service.Routes) != 0 {
+			reg.services[i].firstRoute = weak.Make(&service.Routes[0])
+		}
+		reg.routes[i] = make([]*RouteCoverage, len(service.Routes))
+		for j, route := range service.Routes {
+			reg.routes[i][j] = coverage.register(service, route, j)
+		}
+	}
+	if coverage.registered == nil {
+		coverage.registered = map[weak.Pointer[Service]]*registration{}
+	}
+	if !ok {
+		// the cache is removed when the services are garbage collected
+		runtime.AddCleanup(&services[0], coverage.unregister, key)
+	}
+	coverage.registered[key] = reg
+	return reg
+}
+
+// unregister removes the cache of the garbage collected services.
+func (coverage *Coverage) unregister(key weak.Pointer[Service]) {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+	delete(coverage.registered, key)
+}
+
+// isFor returns whether the registration is for the services.
+// The services may be changed in place after the registration.
+func (reg *registration) isFor(services []Service) bool {
+	if len(reg.services) != len(services) {
+		return false
+	}
+	for i, service := range services {
+		registered := reg.services[i]
+		if service.Endpoint != registered.endpoint || len(service.Routes) != registered.routes {
+			return false
+		}
+		if len(service.Routes) != 0 && weak.Make(&service.Routes[0]) != registered.firstRoute {
+			return false
+		}
+	}
+	return true
+}
+
+// register registers the route and returns the route coverage.
+// coverage.mu must be locked.
+func (coverage *Coverage) register(service Service, route Route, index int) *RouteCoverage {
+	key := routeKey{
+		endpoint: service.Endpoint,
+		name:     route.Name,
+	}
+	if route.Name == "" {
+		key.matcher = fmt.Sprintf("%#v", route.Matcher)
+	}
+	if i, ok := coverage.indexes[key]; ok {
+		return coverage.routes[i]
+	}
+	if coverage.indexes == nil {
+		coverage.indexes = map[routeKey]int{}
+	}
+	coverage.indexes[key] = len(coverage.routes)
+	rc := &RouteCoverage{
+		Endpoint: service.Endpoint,
+		Name:     route.Name,
+		Index:    index,
+	}
+	coverage.routes = append(coverage.routes, rc)
+	return rc
+}
+
+// hit increments the hits of the route.
+func (coverage *Coverage) hit(rc *RouteCoverage) {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+	rc.Hits++
+}
+
+// Report returns the coverage report.
+func (coverage *Coverage) Report() CoverageReport {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+	report := CoverageReport{
+		Total:  len(coverage.routes),
+		Routes: make([]*RouteCoverage, len(coverage.routes)),
+	}
+	for i, rc := range coverage.routes {
+		c := *rc
+		report.Routes[i] = &c
+		if rc.Hits != 0 {
+			report.Hit++
+		}
+	}
+	return report
+}
+
+// Reset clears the recorded coverage.
+func (coverage *Coverage) Reset() {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+	coverage.routes = nil
+	coverage.indexes = nil
+	coverage.registered = nil
+}
+
+// WriteJSON writes the coverage report as JSON.
+func (coverage *Coverage) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(coverage.Report()); err != nil {
+		return fmt.Errorf("encode the coverage report as JSON: %w", err)
+	}
+	return nil
+}
+
+// WriteText writes the coverage report as text.
+// Routes which were never hit are marked with "(not hit)".
+func (coverage *Coverage) WriteText(w io.Writer) error {
+	report := coverage.Report()
+	percent := 0.0
+	if report.Total != 0 {
+		percent = float64(report.Hit) * 100 / float64(report.Total)
+	}
+	if _, err := fmt.Fprintf(w, "flute route coverage: %d/%d routes (%.1f%%)\n", report.Hit, report.Total, percent); err != nil {
+		return fmt.Errorf("write the coverage report: %w", err)
+	}
+	endpoint := ""
+	for i, rc := range report.Routes {
+		if i == 0 || rc.Endpoint != endpoint {
+			endpoint = rc.Endpoint
+			if _, err := fmt.Fprintln(w, endpoint); err != nil {
+				return fmt.Errorf("write the coverage report: %w", err)
+			}
+		}
+		name := rc.Name
+		if name == "" {
+			name = "#" + strconv.Itoa(rc.Index)
+		}
+		if rc.Hits == 0 {
+			name += " (not hit)"
+		}
+		if _, err := fmt.Fprintf(w, "  %5d  %s\n", rc.Hits, name); err != nil {
+			return fmt.Errorf("write the coverage report: %w", err)
+		}
+	}
+	return nil
+}
+
+func (transport Transport) coverage() *Coverage {
+	if transport.Coverage != nil {
+		return transport.Coverage
+	}
+	return DefaultCoverage
+}
+
+// registerTransport registers the routes of the transport and returns their route coverages.
+func (coverage *Coverage) registerTransport(transport Transport) *registration {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+	return coverage.registerServices(transport.Services)
+}
@@ -0,0 +1,187 @@
+package flute_test
+
+import (
+	"bytes"
+	"net/http"
+	"runtime"
+	"testing"
+	"weak"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestCoverage(t *testing.T) {
+	coverage := &flute.Coverage{}
+	services := []flute.Service{
+		{
+			Endpoint: "http://example.com",
+			Routes: []flute.Route{
+				{
+					Name: "get a user",
+					Matcher: flute.Matcher{
+						Method: http.MethodGet,
+					},
+				},
+				{
+					Matcher: flute.Matcher{
+						Method: http.MethodPost,
+					},
+				},
+			},
+		},
+	}
+	transport := flute.Transport{
+		T:        t,
+		Coverage: coverage,
+		Services: services,
+	}
+	client := &http.Client{Transport: transport}
+	for range 2 {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users/10", nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+	coverage.Register(flute.Service{
+		Endpoint: "http://api.example.com",
+		Routes: []flute.Route{
+			{
+				Name: "list users",
+			},
+		},
+	})
+
+	data := []struct {
+		title  string
+		write  func(buf *bytes.Buffer) error
+		isJSON bool
+		exp    string
+	}{
+		{
+			title: "text",
+			write: func(buf *bytes.Buffer) error {
+				return coverage.WriteText(buf)
+			},
+			exp: `flute route coverage: 1/3 routes (33.3%)
+http://example.com
+      2  get a user
+      0  #1 (not hit)
+http://api.example.com
+      0  list users (not hit)
+`,
+		},
+		{
+			title: "json",
+			write: func(buf *bytes.Buffer) error {
+				return coverage.WriteJSON(buf)
+			},
+			isJSON: true,
+			exp: `{
+  "total": 3,
+  "hit": 1,
+  "routes": [
+    {"endpoint": "http://example.com", "name": "get a user", "index": 0, "hits": 2},
+    {"endpoint": "http://example.com", "index": 1, "hits": 0},
+    {"endpoint": "http://api.example.com", "name": "list users", "index": 0, "hits": 0}
+  ]
+}`,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, d.write(buf))
+			if d.isJSON {
+				require.JSONEq(t, d.exp, buf.String())
+				return
+			}
+			require.Equal(t, d.exp, buf.String())
+		})
+	}
+
+	coverage.Reset()
+	require.Equal(t, flute.CoverageReport{Routes: []*flute.RouteCoverage{}}, coverage.Report())
+}
+
+func TestCoverage_derive(t *testing.T) {
+	coverage := &flute.Coverage{}
+	base := flute.Transport{
+		T:        t,
+		Coverage: coverage,
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Name: "get a user",
+						Matcher: flute.Matcher{
+							Method: http.MethodGet,
+						},
+					},
+					{
+						Matcher: flute.Matcher{
+							Method: http.MethodPost,
+						},
+					},
+				},
+			},
+		},
+	}
+	derived := base.Derive(t, flute.Service{
+		Endpoint: "http://example.com",
+		Routes: []flute.Route{
+			{
+				Name: "delete a user",
+				Matcher: flute.Matcher{
+					Method: http.MethodDelete,
+				},
+			},
+		},
+	})
+	for _, transport := range []flute.Transport{base, derived, derived} {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users/10", nil)
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+	require.Equal(t, flute.CoverageReport{
+		Total: 3,
+		Hit:   1,
+		Routes: []*flute.RouteCoverage{
+			{Endpoint: "http://example.com", Name: "get a user", Hits: 3},
+			{Endpoint: "http://example.com", Index: 1},
+			{Endpoint: "http://example.com", Name: "delete a user"},
+		},
+	}, coverage.Report())
+}
+
+func TestCoverage_release(t *testing.T) {
+	coverage := &flute.Coverage{}
+	// the transport is dropped when the function returns
+	roundTrip := func() weak.Pointer[flute.Service] {
+		transport := flute.Transport{
+			T:        t,
+			Coverage: coverage,
+			Services: []flute.Service{
+				{
+					Endpoint: "http://example.com",
+					Routes:   []flute.Route{statusRoute("get a user", http.StatusOK)},
+				},
+			},
+		}
+		require.Equal(t, http.StatusOK, getStatusCode(t, transport))
+		return weak.Make(&transport.Services[0])
+	}
+	services := roundTrip()
+	for range 10 {
+		runtime.GC()
+		if services.Value() == nil {
+			break
+		}
+	}
+	require.Nil(t, services.Value(), "the coverage keeps the services of the dropped transport alive")
+	require.Equal(t, 1, coverage.Report().Hit)
+}
@@ -0,0 +1,76 @@
+package flute
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// makeCurlCommand returns the curl command which reproduces the request.
+// The secrets are redacted.
+func makeCurlCommand(req *http.Request, body []byte, redaction *Redaction) string {
+	args := []string{"curl"}
+	if req.Method != "" && (req.Method != http.MethodGet || len(body) != 0) {
+		args = append(args, "-X", req.Method)
+	}
+	if req.URL != nil {
+		args = append(args, shellQuote(redaction.url(req.URL).String()))
+	}
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range req.Header[k] {
+			if redaction.isSecretHeader(k) {
+				v = redactedValue
+			}
+			args = append(args, "-H", shellQuote(k+": "+v))
+		}
+	}
+	if len(body) != 0 {
+		args = append(args, "--data-raw", shellQuote(string(redaction.body(body, req.Header.Get("Content-Type")))))
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes the string with single quotes for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// curlReporter is the Reporter which appends the curl command to the failure messages.
+type curlReporter struct {
+	Reporter
+
+	curl string
+}
+
+func newCurlReporter(rep Reporter, req *http.Request, body []byte, redaction *Redaction) curlReporter {
+	return curlReporter{
+		Reporter: rep,
+		curl:     makeCurlCommand(req, body, redaction),
+	}
+}
+
+func (rep curlReporter) withCurl(msg string) string {
+	return msg + "\ncurl: " + rep.curl
+}
+
+func (rep curlReporter) Equal(t testing.TB, expected, actual any, msg string) bool {
+	return rep.Reporter.Equal(t, expected, actual, rep.withCurl(msg))
+}
+
+func (rep curlReporter) JSONEq(t testing.TB, expected, actual, msg string) bool {
+	return rep.Reporter.JSONEq(t, expected, actual, rep.withCurl(msg))
+}
+
+func (rep curlReporter) Fail(t testing.TB, msg string) bool {
+	return rep.Reporter.Fail(t, rep.withCurl(msg))
+}
+
+func (rep curlReporter) FailNow(t testing.TB, msg string) {
+	rep.Reporter.FailNow(t, rep.withCurl(msg))
+}
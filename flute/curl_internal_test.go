@@ -0,0 +1,108 @@
+package flute
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_makeCurlCommand(t *testing.T) {
+	data := []struct {
+		title     string
+		req       *http.Request
+		body      []byte
+		redaction *Redaction
+		exp       string
+	}{
+		{
+			title: "get",
+			req: &http.Request{
+				Method: http.MethodGet,
+				URL: &url.URL{
+					Scheme:   "http",
+					Host:     "example.com",
+					Path:     "/users",
+					RawQuery: "id=10",
+				},
+			},
+			exp: `curl 'http://example.com/users?id=10'`,
+		},
+		{
+			title: "post with secret headers",
+			req: &http.Request{
+				Method: http.MethodPost,
+				URL: &url.URL{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   "/users",
+				},
+				Header: http.Header{
+					"Content-Type":  []string{"application/json"},
+					"Authorization": []string{"token XXXXX"},
+					"Cookie":        []string{"session=XXXXX"},
+				},
+			},
+			body: []byte(`{"name": "foo's"}`),
+			exp:  `curl -X POST 'http://example.com/users' -H 'Authorization: REDACTED' -H 'Content-Type: application/json' -H 'Cookie: REDACTED' --data-raw '{"name": "foo'\''s"}'`,
+		},
+		{
+			title: "secret query and JSON body",
+			req: &http.Request{
+				Method: http.MethodPost,
+				URL: &url.URL{
+					Scheme:   "http",
+					Host:     "example.com",
+					Path:     "/login",
+					RawQuery: "token=XXXXX&id=10",
+				},
+			},
+			body: []byte(`{"user": {"name": "foo", "password": "XXXXX"}}`),
+			exp:  `curl -X POST 'http://example.com/login?token=REDACTED&id=10' --data-raw '{"user":{"name":"foo","password":"REDACTED"}}'`,
+		},
+		{
+			title: "form body",
+			req: &http.Request{
+				Method: http.MethodPost,
+				URL: &url.URL{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   "/token",
+				},
+				Header: http.Header{
+					"Content-Type": []string{"application/x-www-form-urlencoded"},
+				},
+			},
+			body: []byte(`grant_type=client_credentials&client_secret=XXXXX`),
+			exp:  `curl -X POST 'http://example.com/token' -H 'Content-Type: application/x-www-form-urlencoded' --data-raw 'grant_type=client_credentials&client_secret=REDACTED'`,
+		},
+		{
+			title: "redaction is disabled",
+			req: &http.Request{
+				Method: http.MethodGet,
+				URL: &url.URL{
+					Scheme:   "http",
+					Host:     "example.com",
+					Path:     "/users",
+					RawQuery: "token=XXXXX",
+				},
+				Header: http.Header{
+					"Authorization": []string{"token XXXXX"},
+				},
+			},
+			redaction: &Redaction{},
+			exp:       `curl 'http://example.com/users?token=XXXXX' -H 'Authorization: token XXXXX'`,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			redaction := d.redaction
+			if redaction == nil {
+				redaction = &DefaultRedaction
+			}
+			require.Equal(t, d.exp, makeCurlCommand(d.req, d.body, redaction))
+		})
+	}
+}
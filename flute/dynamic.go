@@ -0,0 +1,175 @@
+package flute
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// ErrRouteNotFound is returned when the route to replace or remove isn't found.
+var ErrRouteNotFound = errors.New("flute: route isn't found")
+
+// DynamicTransport is an http.RoundTripper whose routes can be added, replaced and removed while requests are sent.
+// It is safe for concurrent use.
+// A request which is being handled isn't affected by the changes.
+// DynamicTransport must not be copied after first use.
+type DynamicTransport struct {
+	mu        sync.RWMutex
+	transport Transport
+	// overrides are the layers added by Override
+	overrides []*override
+	// current is transport with overrides applied
+	current Transport
+}
+
+// NewDynamicTransport returns a DynamicTransport which starts with the transport's services.
+// The transport's services are copied, so changing them after calling NewDynamicTransport doesn't affect the DynamicTransport.
+func NewDynamicTransport(transport Transport) *DynamicTransport {
+	transport.Services = cloneServices(transport.Services)
+	return &DynamicTransport{
+		transport: transport,
+		current:   transport,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (dt *DynamicTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return dt.Transport().RoundTrip(req)
+}
+
+// Transport returns the snapshot of the current transport.
+func (dt *DynamicTransport) Transport() Transport {
+	dt.mu.RLock()
+	defer dt.mu.RUnlock()
+	// the services are never changed in place, so the snapshot can share them
+	return dt.current
+}
+
+// Services returns the copy of the current services including the overrides.
+func (dt *DynamicTransport) Services() []Service {
+	dt.mu.RLock()
+	defer dt.mu.RUnlock()
+	return cloneServices(dt.current.Services)
+}
+
+// SetServices replaces all services.
+func (dt *DynamicTransport) SetServices(services ...Service) {
+	services = cloneServices(services)
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	dt.transport.Services = services
+	dt.applyOverrides()
+}
+
+// AddService adds the service.
+// If the service of the same endpoint exists, the service is replaced.
+func (dt *DynamicTransport) AddService(service Service) {
+	service.Routes = slices.Clone(service.Routes)
+	_ = dt.update(func(services []Service) ([]Service, error) {
+		if i := indexOfService(services, service.Endpoint); i != -1 {
+			services[i] = service
+			return services, nil
+		}
+		return append(services, service), nil
+	})
+}
+
+// RemoveService removes the service of the endpoint.
+// If the service isn't found, RemoveService does nothing.
+func (dt *DynamicTransport) RemoveService(endpoint string) {
+	_ = dt.update(func(services []Service) ([]Service, error) {
+		return slices.DeleteFunc(services, func(service Service) bool {
+			return service.Endpoint == endpoint
+		}), nil
+	})
+}
+
+// AddRoute adds the route to the end of the service's routes.
+// If the service of the endpoint isn't found, the service is added.
+func (dt *DynamicTransport) AddRoute(endpoint string, route Route) {
+	_ = dt.update(func(services []Service) ([]Service, error) {
+		i := indexOfService(services, endpoint)
+		if i == -1 {
+			return append(services, Service{
+				Endpoint: endpoint,
+				Routes:   []Route{route},
+			}), nil
+		}
+		services[i].Routes = append(services[i].Routes, route)
+		return services, nil
+	})
+}
+
+// ReplaceRoute replaces the route of the name in the service of the endpoint.
+// If the route isn't found, ReplaceRoute returns ErrRouteNotFound.
+func (dt *DynamicTransport) ReplaceRoute(endpoint, name string, route Route) error {
+	return dt.update(func(services []Service) ([]Service, error) {
+		i, j := indexOfRoute(services, endpoint, name)
+		if j == -1 {
+			return nil, fmt.Errorf("replace the route %q of %s: %w", name, endpoint, ErrRouteNotFound)
+		}
+		services[i].Routes[j] = route
+		return services, nil
+	})
+}
+
+// RemoveRoute removes the route of the name from the service of the endpoint.
+// If the route isn't found, RemoveRoute returns ErrRouteNotFound.
+func (dt *DynamicTransport) RemoveRoute(endpoint, name string) error {
+	return dt.update(func(services []Service) ([]Service, error) {
+		i, j := indexOfRoute(services, endpoint, name)
+		if j == -1 {
+			return nil, fmt.Errorf("remove the route %q of %s: %w", name, endpoint, ErrRouteNotFound)
+		}
+		services[i].Routes = slices.Delete(services[i].Routes, j, j+1)
+		return services, nil
+	})
+}
+
+// update changes the copy of the services and replaces the services with it.
+// The current services are never changed in place because requests being handled may refer to them.
+// The overrides are applied to the changed services again.
+func (dt *DynamicTransport) update(fn func(services []Service) ([]Service, error)) error {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	services, err := fn(cloneServices(dt.transport.Services))
+	if err != nil {
+		return err
+	}
+	dt.transport.Services = services
+	dt.applyOverrides()
+	return nil
+}
+
+// cloneServices copies the services and their routes.
+func cloneServices(services []Service) []Service {
+	if services == nil {
+		return nil
+	}
+	cloned := make([]Service, len(services))
+	for i, service := range services {
+		service.Routes = slices.Clone(service.Routes)
+		cloned[i] = service
+	}
+	return cloned
+}
+
+func indexOfService(services []Service, endpoint string) int {
+	return slices.IndexFunc(services, func(service Service) bool {
+		return service.Endpoint == endpoint
+	})
+}
+
+// indexOfRoute returns the indexes of the service and the route.
+// If the route isn't found, the index of the route is -1.
+func indexOfRoute(services []Service, endpoint, name string) (int, int) {
+	i := indexOfService(services, endpoint)
+	if i == -1 {
+		return -1, -1
+	}
+	return i, slices.IndexFunc(services[i].Routes, func(route Route) bool {
+		return route.Name == name
+	})
+}
@@ -0,0 +1,147 @@
+package flute_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func statusRoute(name string, statusCode int) flute.Route {
+	return flute.Route{
+		Name: name,
+		Matcher: flute.Matcher{
+			Method: http.MethodGet,
+		},
+		Response: flute.Response{
+			Base: http.Response{
+				StatusCode: statusCode,
+			},
+		},
+	}
+}
+
+func getStatusCode(t *testing.T, transport http.RoundTripper) int {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users/10", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestDynamicTransport(t *testing.T) {
+	transport := flute.NewDynamicTransport(flute.Transport{
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					statusRoute("get a user", http.StatusOK),
+				},
+			},
+		},
+	})
+	data := []struct {
+		title      string
+		update     func() error
+		statusCode int
+		isErr      bool
+	}{
+		{
+			title:      "initial routes",
+			update:     func() error { return nil },
+			statusCode: http.StatusOK,
+		},
+		{
+			title: "replace the route",
+			update: func() error {
+				return transport.ReplaceRoute("http://example.com", "get a user", statusRoute("get a user", http.StatusServiceUnavailable))
+			},
+			statusCode: http.StatusServiceUnavailable,
+		},
+		{
+			title: "the route to replace isn't found",
+			update: func() error {
+				return transport.ReplaceRoute("http://example.com", "list users", statusRoute("list users", http.StatusOK))
+			},
+			statusCode: http.StatusServiceUnavailable,
+			isErr:      true,
+		},
+		{
+			title: "remove the route",
+			update: func() error {
+				return transport.RemoveRoute("http://example.com", "get a user")
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			title: "the route to remove isn't found",
+			update: func() error {
+				return transport.RemoveRoute("http://example.com", "get a user")
+			},
+			statusCode: http.StatusNotFound,
+			isErr:      true,
+		},
+		{
+			title: "add the route",
+			update: func() error {
+				transport.AddRoute("http://example.com", statusRoute("get a user", http.StatusAccepted))
+				return nil
+			},
+			statusCode: http.StatusAccepted,
+		},
+		{
+			title: "remove the service",
+			update: func() error {
+				transport.RemoveService("http://example.com")
+				return nil
+			},
+			statusCode: http.StatusNotFound,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			err := d.update()
+			if d.isErr {
+				require.ErrorIs(t, err, flute.ErrRouteNotFound)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, d.statusCode, getStatusCode(t, transport))
+		})
+	}
+}
+
+func TestDynamicTransport_concurrency(t *testing.T) {
+	transport := flute.NewDynamicTransport(flute.Transport{})
+	transport.AddService(flute.Service{
+		Endpoint: "http://example.com",
+		Routes: []flute.Route{
+			statusRoute("get a user", http.StatusOK),
+		},
+	})
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			for range 10 {
+				transport.AddRoute("http://example.com", statusRoute("", http.StatusOK))
+				req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users/10", nil)
+				if !assert.NoError(t, err) {
+					return
+				}
+				resp, err := transport.RoundTrip(req)
+				if !assert.NoError(t, err) {
+					return
+				}
+				resp.Body.Close()
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			}
+		})
+	}
+	wg.Wait()
+	require.Len(t, transport.Services()[0].Routes, 101)
+}
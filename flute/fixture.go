@@ -0,0 +1,207 @@
+package flute
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Fixture is the declarative definition of services, which is written in YAML.
+	// Fixtures can be shared between Go tests and the flute command.
+	//
+	//	services:
+	//	  - endpoint: http://example.com
+	//	    routes:
+	//	      - name: create a user
+	//	        matcher:
+	//	          method: POST
+	//	          path: /users
+	//	        tester:
+	//	          body_json:
+	//	            name: foo
+	//	        response:
+	//	          status: 201
+	//	          body_json:
+	//	            id: 10
+	//	            name: foo
+	Fixture struct {
+		Services []FixtureService `yaml:"services"`
+	}
+
+	// FixtureService is the fixture of Service.
+	FixtureService struct {
+		Endpoint string         `yaml:"endpoint"`
+		Routes   []FixtureRoute `yaml:"routes"`
+	}
+
+	// FixtureRoute is the fixture of Route.
+	FixtureRoute struct {
+		Name     string          `yaml:"name"`
+		Matcher  FixtureRequest  `yaml:"matcher"`
+		Tester   FixtureRequest  `yaml:"tester"`
+		Response FixtureResponse `yaml:"response"`
+	}
+
+	// FixtureRequest is the fixture of Matcher and Tester.
+	// Query and Header are converted to PartOfQuery and PartOfHeader.
+	// PathTemplate is used only by the matcher.
+	FixtureRequest struct {
+		Method         string                    `yaml:"method"`
+		Path           string                    `yaml:"path"`
+		PathTemplate   string                    `yaml:"path_template"`
+		Query          map[string]FixtureStrings `yaml:"query"`
+		Header         map[string]FixtureStrings `yaml:"header"`
+		BodyString     string                    `yaml:"body_string"`
+		BodyJSON       any                       `yaml:"body_json"`
+		BodyJSONString string                    `yaml:"body_json_string"`
+		BodyFile       string                    `yaml:"body_file"`
+	}
+
+	// FixtureResponse is the fixture of Response.
+	FixtureResponse struct {
+		Status     int                       `yaml:"status"`
+		Header     map[string]FixtureStrings `yaml:"header"`
+		BodyString string                    `yaml:"body_string"`
+		BodyJSON   any                       `yaml:"body_json"`
+		BodyFile   string                    `yaml:"body_file"`
+	}
+
+	// FixtureStrings is a list of strings which can also be written as a single string.
+	// An empty list is written as null, which means only the existence of the key is checked.
+	FixtureStrings []string
+)
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (strs *FixtureStrings) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			*strs = nil
+			return nil
+		}
+		*strs = FixtureStrings{node.Value}
+		return nil
+	}
+	var arr []string
+	if err := node.Decode(&arr); err != nil {
+		return err
+	}
+	*strs = arr
+	return nil
+}
+
+// LoadFixture reads the fixture file and returns the services.
+// BodyFile is read relative to the directory of the fixture file.
+func LoadFixture(path string) ([]Service, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open the fixture file: %w", err)
+	}
+	defer f.Close()
+	services, err := ReadFixture(f, os.DirFS(filepath.Dir(path)))
+	if err != nil {
+		return nil, fmt.Errorf("read the fixture file %s: %w", path, err)
+	}
+	return services, nil
+}
+
+// ReadFixture reads the fixture from r and returns the services.
+// BodyFile is read from fsys.
+// If fsys is nil, BodyFile is read from the local file system.
+func ReadFixture(r io.Reader, fsys fs.FS) ([]Service, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	fixture := Fixture{}
+	if err := decoder.Decode(&fixture); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode the fixture as YAML: %w", err)
+	}
+	return fixture.ServiceList(fsys), nil
+}
+
+// ServiceList converts the fixture to services.
+// BodyFile is read from fsys.
+func (fixture Fixture) ServiceList(fsys fs.FS) []Service {
+	services := make([]Service, len(fixture.Services))
+	for i, service := range fixture.Services {
+		routes := make([]Route, len(service.Routes))
+		for j, fr := range service.Routes {
+			routes[j] = fr.route(fsys)
+		}
+		services[i] = Service{
+			Endpoint: service.Endpoint,
+			Routes:   routes,
+		}
+	}
+	return services
+}
+
+func (fr FixtureRoute) route(fsys fs.FS) Route {
+	req := fr.Matcher
+	tester := fr.Tester
+	resp := fr.Response
+	return Route{
+		Name: fr.Name,
+		Matcher: Matcher{
+			Method:         req.Method,
+			Path:           req.Path,
+			PathTemplate:   req.PathTemplate,
+			PartOfQuery:    fixtureQuery(req.Query),
+			PartOfHeader:   fixtureHeader(req.Header),
+			BodyString:     req.BodyString,
+			BodyJSON:       req.BodyJSON,
+			BodyJSONString: req.BodyJSONString,
+			BodyFile:       req.BodyFile,
+			FS:             fsys,
+		},
+		Tester: Tester{
+			Method:         tester.Method,
+			Path:           tester.Path,
+			PartOfQuery:    fixtureQuery(tester.Query),
+			PartOfHeader:   fixtureHeader(tester.Header),
+			BodyString:     tester.BodyString,
+			BodyJSON:       tester.BodyJSON,
+			BodyJSONString: tester.BodyJSONString,
+			BodyFile:       tester.BodyFile,
+			FS:             fsys,
+		},
+		Response: Response{
+			Base: http.Response{
+				StatusCode: resp.Status,
+				Header:     fixtureHeader(resp.Header),
+			},
+			BodyString: resp.BodyString,
+			BodyJSON:   resp.BodyJSON,
+			BodyFile:   resp.BodyFile,
+			FS:         fsys,
+		},
+	}
+}
+
+func fixtureQuery(m map[string]FixtureStrings) url.Values {
+	if m == nil {
+		return nil
+	}
+	query := make(url.Values, len(m))
+	for k, v := range m {
+		query[k] = v
+	}
+	return query
+}
+
+func fixtureHeader(m map[string]FixtureStrings) http.Header {
+	if m == nil {
+		return nil
+	}
+	header := make(http.Header, len(m))
+	for k, v := range m {
+		header[http.CanonicalHeaderKey(k)] = v
+	}
+	return header
+}
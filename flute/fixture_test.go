@@ -0,0 +1,123 @@
+package flute_test
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestLoadFixture(t *testing.T) {
+	services, err := flute.LoadFixture("testdata/mocks.yaml")
+	require.NoError(t, err)
+	fsys := os.DirFS("testdata")
+	require.Equal(t, []flute.Service{
+		{
+			Endpoint: "http://example.com",
+			Routes: []flute.Route{
+				{
+					Name: "create a user",
+					Matcher: flute.Matcher{
+						Method: http.MethodPost,
+						Path:   "/users",
+						PartOfHeader: http.Header{
+							"Authorization": []string{"token XXXXX"},
+						},
+						FS: fsys,
+					},
+					Tester: flute.Tester{
+						BodyJSON: map[string]any{"name": "foo"},
+						FS:       fsys,
+					},
+					Response: flute.Response{
+						Base: http.Response{
+							StatusCode: http.StatusCreated,
+							Header: http.Header{
+								"X-Request-Id": []string{"10"},
+							},
+						},
+						BodyJSON: map[string]any{"id": 10, "name": "foo"},
+						FS:       fsys,
+					},
+				},
+				{
+					Name: "get a user",
+					Matcher: flute.Matcher{
+						Method:       http.MethodGet,
+						PathTemplate: "/users/{id}",
+						PartOfQuery: url.Values{
+							"fields": []string{"name", "email"},
+						},
+						FS: fsys,
+					},
+					Tester: flute.Tester{
+						FS: fsys,
+					},
+					Response: flute.Response{
+						BodyFile: "hello.txt",
+						FS:       fsys,
+					},
+				},
+			},
+		},
+	}, services)
+}
+
+func TestReadFixture(t *testing.T) {
+	data := []struct {
+		title string
+		yaml  string
+		exp   []flute.Service
+		isErr bool
+	}{
+		{
+			title: "empty",
+			exp:   []flute.Service{},
+		},
+		{
+			title: "header without values",
+			yaml: `services:
+  - endpoint: http://example.com
+    routes:
+      - matcher:
+          header:
+            Authorization: null`,
+			exp: []flute.Service{
+				{
+					Endpoint: "http://example.com",
+					Routes: []flute.Route{
+						{
+							Matcher: flute.Matcher{
+								PartOfHeader: http.Header{
+									"Authorization": nil,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			title: "unknown field",
+			yaml: `services:
+  - endpoint: http://example.com
+    route: []`,
+			isErr: true,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			services, err := flute.ReadFixture(strings.NewReader(d.yaml), nil)
+			if d.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, d.exp, services)
+		})
+	}
+}
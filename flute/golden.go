@@ -0,0 +1,133 @@
+package flute
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// updateGolden is set by the -flute.update flag.
+// If it is true, the golden files are rewritten with the actual request bodies.
+var updateGolden bool //nolint:gochecknoglobals
+
+// init registers the -flute.update flag only in test binaries,
+// so that programs importing flute such as the flute command don't get the flag.
+func init() { //nolint:gochecknoinits
+	if testing.Testing() {
+		flag.BoolVar(&updateGolden, "flute.update", false, "update the golden files of flute")
+	}
+}
+
+// goldenFilePath returns the path of the golden file.
+// A relative path is resolved from the testdata directory.
+func goldenFilePath(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join("testdata", name)
+}
+
+// normalizeGolden formats JSON with sorted keys and indentation so that the formatting doesn't matter.
+// If b isn't JSON, b is returned as it is.
+func normalizeGolden(b []byte) []byte {
+	if !json.Valid(b) {
+		return b
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return b
+	}
+	normalized, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return b
+	}
+	return append(normalized, '\n')
+}
+
+func testGoldenFile(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
+	if route.Tester.GoldenFile == "" {
+		return
+	}
+	var b []byte
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			rep.Fail(
+				t, makeMsg(
+					fmt.Sprintf("failed to read the request body: %v", err),
+					service.Endpoint, route.Name))
+			return
+		}
+		b = body
+	}
+	actual := normalizeGolden(b)
+	p := goldenFilePath(route.Tester.GoldenFile)
+
+	if updateGolden {
+		if err := writeGoldenFile(p, actual); err != nil {
+			rep.Fail(t, makeMsg(err.Error(), service.Endpoint, route.Name))
+			return
+		}
+		t.Logf("updated the golden file %s", p)
+		return
+	}
+
+	expected, err := os.ReadFile(p)
+	if err != nil {
+		rep.Fail(
+			t, makeMsg(
+				fmt.Sprintf("failed to read the golden file (run the test with -flute.update to create it): %v", err),
+				service.Endpoint, route.Name))
+		return
+	}
+	expected = normalizeGolden(expected)
+	if bytes.Equal(expected, actual) {
+		return
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(expected)),
+		B:        splitLines(string(actual)),
+		FromFile: p,
+		ToFile:   "request body",
+		Context:  3,
+	})
+	if err != nil {
+		rep.Fail(t, makeMsg(fmt.Sprintf("failed to get the diff of the golden file: %v", err), service.Endpoint, route.Name))
+		return
+	}
+	rep.Fail(
+		t, makeMsg(
+			"request body should match the golden file (run the test with -flute.update to update it)\n"+diff,
+			service.Endpoint, route.Name))
+}
+
+func writeGoldenFile(p string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("create the directory of the golden file %s: %w", p, err)
+	}
+	if err := os.WriteFile(p, b, 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("write the golden file %s: %w", p, err)
+	}
+	return nil
+}
+
+// splitLines splits s into lines which keep the line endings.
+// Unlike difflib.SplitLines, splitLines doesn't add an empty line at the end.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,112 @@
+package flute_test
+
+import (
+	"flag"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func roundTripGolden(t *testing.T, rep flute.Reporter, goldenFile, body string) {
+	t.Helper()
+	transport := flute.Transport{
+		T:        t,
+		Reporter: rep,
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Name: "create a user",
+						Tester: flute.Tester{
+							BodyJSONString: `{"name": "foo", "age": 10}`,
+							GoldenFile:     goldenFile,
+						},
+					},
+				},
+			},
+		},
+	}
+	resp, err := transport.RoundTrip(&http.Request{
+		URL: &url.URL{
+			Scheme: "http",
+			Host:   "example.com",
+			Path:   "/users",
+		},
+		Method: http.MethodPost,
+		Body:   io.NopCloser(strings.NewReader(body)),
+	})
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestTester_GoldenFile(t *testing.T) {
+	data := []struct {
+		title      string
+		goldenFile string
+		body       string
+		msgs       []string
+	}{
+		{
+			title:      "the formatting is normalized",
+			goldenFile: "create_user.golden.json",
+			body:       `{"name":"foo","age":10}`,
+		},
+		{
+			title:      "diff",
+			goldenFile: "create_user.golden.json",
+			body:       `{"name": "foo", "age": 11}`,
+			msgs: []string{
+				`request body should match
+service: http://example.com
+request name: create a user
+curl: curl -X POST 'http://example.com/users' --data-raw '{"name": "foo", "age": 11}'`,
+				`request body should match the golden file (run the test with -flute.update to update it)
+--- testdata/create_user.golden.json
++++ request body
+@@ -1,4 +1,4 @@
+ {
+-  "age": 10,
++  "age": 11,
+   "name": "foo"
+ }
+
+service: http://example.com
+request name: create a user
+curl: curl -X POST 'http://example.com/users' --data-raw '{"name": "foo", "age": 11}'`,
+			},
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			rep := &recordReporter{}
+			roundTripGolden(t, rep, d.goldenFile, d.body)
+			require.Equal(t, d.msgs, rep.msgs)
+		})
+	}
+}
+
+func TestTester_GoldenFile_update(t *testing.T) {
+	require.NoError(t, flag.Set("flute.update", "true"))
+	t.Cleanup(func() {
+		require.NoError(t, flag.Set("flute.update", "false"))
+	})
+	p := filepath.Join(t.TempDir(), "golden", "create_user.json")
+	rep := &recordReporter{}
+	roundTripGolden(t, rep, p, `{"name":"foo","age":10}`)
+	require.Empty(t, rep.msgs)
+	b, err := os.ReadFile(p)
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "age": 10,
+  "name": "foo"
+}
+`, string(b))
+}
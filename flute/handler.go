@@ -0,0 +1,70 @@
+package flute
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Handler is an http.Handler which serves the responses of the transport over HTTP.
+// It is used to share the mocks with programs other than Go tests.
+type Handler struct {
+	// Transport returns the responses. Transport is typically Transport or *DynamicTransport.
+	Transport http.RoundTripper
+	// Endpoint is the scheme and host such as "http://example.com" which the requests are sent to.
+	// If Endpoint is empty, the scheme is "http" and the host is the request's Host header.
+	Endpoint string
+}
+
+// ServeHTTP implements http.Handler.
+// If the transport returns an error, ServeHTTP responds 502 Bad Gateway.
+func (handler Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := r.Clone(r.Context())
+	req.RequestURI = ""
+	req.URL.Scheme = "http"
+	req.URL.Host = r.Host
+	if handler.Endpoint != "" {
+		endpoint, err := url.Parse(handler.Endpoint)
+		if err != nil {
+			http.Error(w, "parse the endpoint: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		req.URL.Scheme = endpoint.Scheme
+		req.URL.Host = endpoint.Host
+		req.Host = endpoint.Host
+	}
+	resp, err := handler.Transport.RoundTrip(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	header := w.Header()
+	for k, v := range resp.Header {
+		header[k] = v
+	}
+	statusCode := resp.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	w.WriteHeader(statusCode)
+	if !isStreamResponse(resp) {
+		_, _ = io.Copy(w, resp.Body)
+		return
+	}
+	// flush each chunk so that the client receives the stream as it is sent
+	rc := http.NewResponseController(w)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return
+			}
+			_ = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package flute_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestHandler(t *testing.T) {
+	services, err := flute.LoadFixture("testdata/mocks.yaml")
+	require.NoError(t, err)
+	server := httptest.NewServer(flute.Handler{
+		Transport: flute.Transport{
+			Services: services,
+		},
+		Endpoint: "http://example.com",
+	})
+	defer server.Close()
+
+	data := []struct {
+		title      string
+		method     string
+		path       string
+		header     http.Header
+		body       string
+		statusCode int
+		exp        string
+	}{
+		{
+			title:  "create a user",
+			method: http.MethodPost,
+			path:   "/users",
+			header: http.Header{
+				"Authorization": []string{"token XXXXX"},
+			},
+			body:       `{"name": "foo"}`,
+			statusCode: http.StatusCreated,
+			exp:        `{"id":10,"name":"foo"}`,
+		},
+		{
+			title:      "get a user",
+			method:     http.MethodGet,
+			path:       "/users/10?fields=name&fields=email",
+			statusCode: http.StatusOK,
+			exp:        "hello",
+		},
+		{
+			title:      "no route matches",
+			method:     http.MethodGet,
+			path:       "/users",
+			statusCode: http.StatusNotFound,
+			exp:        `{"message": "no route matches the request"}`,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), d.method, server.URL+d.path, strings.NewReader(d.body))
+			require.NoError(t, err)
+			for k, v := range d.header {
+				req.Header[k] = v
+			}
+			resp, err := server.Client().Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, d.statusCode, resp.StatusCode)
+			require.Equal(t, d.exp, string(b))
+		})
+	}
+}
@@ -0,0 +1,440 @@
+package flute
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	// Recorder records the requests which the transport handled and the responses.
+	// The responses are recorded as the client received them, including the failures injected by Chaos.
+	// If RoundTrip returns an error, the error is recorded in the custom field "_error" of the response.
+	// The records can be exported as an HTTP Archive (HAR) file.
+	// The zero value is ready to use.
+	Recorder struct {
+		mu      sync.Mutex
+		entries []harEntry
+	}
+
+	harFile struct {
+		Log harLog `json:"log"`
+	}
+
+	harLog struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	}
+
+	harCreator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	harEntry struct {
+		StartedDateTime string      `json:"startedDateTime"`
+		Time            float64     `json:"time"`
+		Request         harRequest  `json:"request"`
+		Response        harResponse `json:"response"`
+		Cache           struct{}    `json:"cache"`
+		Timings         harTimings  `json:"timings"`
+	}
+
+	harRequest struct {
+		Method      string         `json:"method"`
+		URL         string         `json:"url"`
+		HTTPVersion string         `json:"httpVersion"`
+		Cookies     []harNameValue `json:"cookies"`
+		Headers     []harNameValue `json:"headers"`
+		QueryString []harNameValue `json:"queryString"`
+		PostData    *harPostData   `json:"postData,omitempty"`
+		HeadersSize int            `json:"headersSize"`
+		BodySize    int            `json:"bodySize"`
+	}
+
+	harResponse struct {
+		Status      int            `json:"status"`
+		StatusText  string         `json:"statusText"`
+		HTTPVersion string         `json:"httpVersion"`
+		Cookies     []harNameValue `json:"cookies"`
+		Headers     []harNameValue `json:"headers"`
+		Content     harContent     `json:"content"`
+		RedirectURL string         `json:"redirectURL"`
+		HeadersSize int            `json:"headersSize"`
+		BodySize    int            `json:"bodySize"`
+		// Error is the error of RoundTrip. It is the custom field of flute.
+		Error string `json:"_error,omitempty"`
+	}
+
+	harNameValue struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	harPostData struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+	}
+
+	harContent struct {
+		Size     int    `json:"size"`
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text,omitempty"`
+		Encoding string `json:"encoding,omitempty"`
+	}
+
+	harTimings struct {
+		Send    float64 `json:"send"`
+		Wait    float64 `json:"wait"`
+		Receive float64 `json:"receive"`
+	}
+)
+
+// record records the request and the response.
+// If RoundTrip fails, the error is recorded as the response with the status 0 and "_error" instead.
+// The response body is read and restored.
+func (recorder *Recorder) record(req *http.Request, reqBody []byte, resp *http.Response, respErr error, started time.Time, redaction *Redaction) {
+	var harResp harResponse
+	if respErr != nil {
+		harResp = newHARErrorResponse(respErr)
+	} else {
+		var respBody []byte
+		if !isStreamResponse(resp) {
+			respBody = readRecordedBody(resp)
+		}
+		harResp = newHARResponse(resp, respBody, redaction)
+	}
+	elapsed := float64(time.Since(started).Microseconds()) / 1000
+	entry := harEntry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Time:            elapsed,
+		Request:         newHARRequest(req, reqBody, redaction),
+		Response:        harResp,
+		Timings: harTimings{
+			Wait: elapsed,
+		},
+	}
+	recorder.mu.Lock()
+	recorder.entries = append(recorder.entries, entry)
+	recorder.mu.Unlock()
+}
+
+// readRecordedBody reads the response body and restores it.
+// If reading the body fails, for example because Chaos truncates the body,
+// the body read so far is returned and the restored body fails with the same error after it.
+func readRecordedBody(resp *http.Response) []byte {
+	if resp.Body == nil {
+		return nil
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(b), errReader{err: err}))
+		return b
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return b
+}
+
+// Len returns the number of the recorded requests.
+func (recorder *Recorder) Len() int {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return len(recorder.entries)
+}
+
+// WriteHAR writes the recorded requests and responses as an HTTP Archive (HAR).
+func (recorder *Recorder) WriteHAR(w io.Writer) error {
+	recorder.mu.Lock()
+	entries := slices.Clone(recorder.entries)
+	recorder.mu.Unlock()
+	if entries == nil {
+		entries = []harEntry{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(harFile{
+		Log: harLog{
+			Version: "1.2",
+			Creator: harCreator{
+				Name:    "flute",
+				Version: "2",
+			},
+			Entries: entries,
+		},
+	}); err != nil {
+		return fmt.Errorf("encode HAR as JSON: %w", err)
+	}
+	return nil
+}
+
+// SaveHAR writes the recorded requests and responses to the file as an HTTP Archive (HAR).
+func (recorder *Recorder) SaveHAR(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create a HAR file %s: %w", path, err)
+	}
+	if err := recorder.WriteHAR(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close a HAR file %s: %w", path, err)
+	}
+	return nil
+}
+
+func newHARRequest(req *http.Request, body []byte, redaction *Redaction) harRequest {
+	r := harRequest{
+		Method:      req.Method,
+		URL:         redaction.url(req.URL).String(),
+		HTTPVersion: httpVersion(req.Proto),
+		Cookies:     []harNameValue{},
+		Headers:     headerToHAR(redaction.header(req.Header)),
+		QueryString: queryToHAR(redaction.query(req.URL.Query())),
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	if body != nil {
+		r.PostData = &harPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(redaction.body(body, req.Header.Get("Content-Type"))),
+		}
+	}
+	return r
+}
+
+func newHARResponse(resp *http.Response, body []byte, redaction *Redaction) harResponse {
+	body = redaction.body(body, resp.Header.Get("Content-Type"))
+	content := harContent{
+		Size:     len(body),
+		MimeType: resp.Header.Get("Content-Type"),
+	}
+	if utf8.Valid(body) {
+		content.Text = string(body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	}
+	return harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: httpVersion(resp.Proto),
+		Cookies:     []harNameValue{},
+		Headers:     headerToHAR(redaction.header(resp.Header)),
+		Content:     content,
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+}
+
+func newHARErrorResponse(err error) harResponse {
+	return harResponse{
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     []harNameValue{},
+		HeadersSize: -1,
+		Error:       err.Error(),
+	}
+}
+
+func httpVersion(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+	return proto
+}
+
+func headerToHAR(header http.Header) []harNameValue {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	arr := []harNameValue{}
+	for _, k := range keys {
+		for _, v := range header[k] {
+			arr = append(arr, harNameValue{Name: k, Value: v})
+		}
+	}
+	return arr
+}
+
+func queryToHAR(query url.Values) []harNameValue {
+	return headerToHAR(http.Header(query))
+}
+
+// LoadHAR reads an HTTP Archive (HAR) file and converts the entries to services.
+// See ReadHAR.
+func LoadHAR(path string) ([]Service, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open a HAR file %s: %w", path, err)
+	}
+	defer f.Close()
+	return ReadHAR(f)
+}
+
+// ReadHAR reads an HTTP Archive (HAR) and converts the entries to services.
+// A service is created per scheme and host, and a route is created per request.
+// The route matches the request by the method, path, query, and body of the entry,
+// and returns the response of the entry.
+// If the response of the entry has the error recorded by Recorder, the route returns the error.
+// The entries of the same request, such as polling, are merged into one route,
+// which returns the responses in the recorded order and then keeps returning the last response.
+func ReadHAR(r io.Reader) ([]Service, error) {
+	har := harFile{}
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("decode HAR as JSON: %w", err)
+	}
+	services := []Service{}
+	indexes := map[string]int{}
+	// sequences are the responses of the routes by the request
+	sequences := map[harRequestKey]*harSequence{}
+	for i, entry := range har.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("parse the request URL of the entry %d: %w", i, err)
+		}
+		route, err := newRouteFromHAR(entry, u)
+		if err != nil {
+			return nil, fmt.Errorf("convert the entry %d to a route: %w", i, err)
+		}
+		key := newHARRequestKey(entry)
+		if seq, ok := sequences[key]; ok {
+			seq.responses = append(seq.responses, route.Response)
+			services[seq.service].Routes[seq.route].Response = Response{
+				Response: seq.respond,
+			}
+			continue
+		}
+		endpoint := u.Scheme + "://" + u.Host
+		idx, ok := indexes[endpoint]
+		if !ok {
+			idx = len(services)
+			indexes[endpoint] = idx
+			services = append(services, Service{Endpoint: endpoint})
+		}
+		sequences[key] = &harSequence{
+			service:   idx,
+			route:     len(services[idx].Routes),
+			responses: []Response{route.Response},
+		}
+		services[idx].Routes = append(services[idx].Routes, route)
+	}
+	return services, nil
+}
+
+type (
+	// harRequestKey identifies the request of the entry.
+	// The entries of the same key are merged into one route.
+	harRequestKey struct {
+		method string
+		url    string
+		body   string
+	}
+
+	// harSequence returns the responses of the entries of the same request in order.
+	harSequence struct {
+		mu sync.Mutex
+		// service and route are the indexes of the route
+		service   int
+		route     int
+		responses []Response
+		next      int
+	}
+)
+
+func newHARRequestKey(entry harEntry) harRequestKey {
+	key := harRequestKey{
+		method: entry.Request.Method,
+		url:    entry.Request.URL,
+	}
+	if entry.Request.PostData != nil {
+		key.body = entry.Request.PostData.Text
+	}
+	return key
+}
+
+// respond returns the next response.
+// After all responses are returned, the last response is returned repeatedly.
+func (seq *harSequence) respond(req *http.Request) (*http.Response, error) {
+	seq.mu.Lock()
+	resp := seq.responses[seq.next]
+	if seq.next < len(seq.responses)-1 {
+		seq.next++
+	}
+	seq.mu.Unlock()
+	return createHTTPResponse(req, resp)
+}
+
+// harIgnoredResponseHeaders are response headers which are invalid for the decoded response body.
+var harIgnoredResponseHeaders = map[string]struct{}{ //nolint:gochecknoglobals
+	"Content-Encoding":  {},
+	"Content-Length":    {},
+	"Transfer-Encoding": {},
+}
+
+func newRouteFromHAR(entry harEntry, u *url.URL) (Route, error) {
+	route := Route{
+		Name: entry.Request.Method + " " + entry.Request.URL,
+		Matcher: Matcher{
+			Method: entry.Request.Method,
+			Path:   u.Path,
+		},
+		Response: Response{
+			Base: http.Response{
+				StatusCode: entry.Response.Status,
+				Header:     http.Header{},
+			},
+		},
+	}
+	if query := u.Query(); len(query) != 0 {
+		route.Matcher.Query = query
+	}
+	if entry.Response.Error != "" {
+		// the entry of the failed request is converted to the route returning the error
+		err := errors.New(entry.Response.Error)
+		route.Response.Response = func(*http.Request) (*http.Response, error) {
+			return nil, err
+		}
+	}
+	if entry.Request.PostData != nil && entry.Request.PostData.Text != "" {
+		if strings.Contains(entry.Request.PostData.MimeType, "json") {
+			route.Matcher.BodyJSONString = entry.Request.PostData.Text
+		} else {
+			route.Matcher.BodyString = entry.Request.PostData.Text
+		}
+	}
+	for _, h := range entry.Response.Headers {
+		key := http.CanonicalHeaderKey(h.Name)
+		if _, ok := harIgnoredResponseHeaders[key]; ok {
+			continue
+		}
+		route.Response.Base.Header.Add(key, h.Value)
+	}
+	content := entry.Response.Content
+	if content.Encoding == "base64" {
+		b, err := base64.StdEncoding.DecodeString(content.Text)
+		if err != nil {
+			return route, fmt.Errorf("decode the response body as base64: %w", err)
+		}
+		route.Response.BodyString = string(b)
+		return route, nil
+	}
+	route.Response.BodyString = content.Text
+	return route, nil
+}
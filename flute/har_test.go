@@ -0,0 +1,189 @@
+package flute_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestLoadHAR(t *testing.T) {
+	services, err := flute.LoadHAR("testdata/example.har")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	require.Equal(t, "https://api.example.com", services[0].Endpoint)
+	require.Len(t, services[0].Routes, 2)
+
+	client := &http.Client{
+		Transport: flute.Transport{
+			T:        t,
+			Services: services,
+		},
+	}
+	data := []struct {
+		title      string
+		method     string
+		url        string
+		body       string
+		statusCode int
+		exp        string
+	}{
+		{
+			title:      "base64 encoded response body",
+			method:     http.MethodGet,
+			url:        "https://api.example.com/users?id=10",
+			statusCode: http.StatusOK,
+			exp:        `{"id": 10}`,
+		},
+		{
+			title:      "request body",
+			method:     http.MethodPost,
+			url:        "https://api.example.com/users",
+			body:       `{"name":"foo"}`,
+			statusCode: http.StatusCreated,
+			exp:        `{"id": 11, "name": "foo"}`,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), d.method, d.url, strings.NewReader(d.body))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, d.statusCode, resp.StatusCode)
+			require.Equal(t, d.exp, string(b))
+			require.Empty(t, resp.Header.Get("Content-Encoding"))
+		})
+	}
+}
+
+func TestRecorder_WriteHAR(t *testing.T) {
+	recorder := &flute.Recorder{}
+	client := &http.Client{
+		Transport: flute.Transport{
+			T:        t,
+			Recorder: recorder,
+			Services: []flute.Service{
+				{
+					Endpoint: "http://example.com",
+					Routes: []flute.Route{
+						{
+							Name: "create a user",
+							Matcher: flute.Matcher{
+								Method: http.MethodPost,
+								Path:   "/users",
+							},
+							Response: flute.Response{
+								Base: http.Response{
+									StatusCode: http.StatusCreated,
+								},
+								BodyString: `{"id": 10, "name": "foo"}`,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://example.com/users", strings.NewReader(`{"name": "foo"}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "token XXXXX")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, `{"id": 10, "name": "foo"}`, string(b))
+	require.Equal(t, 1, recorder.Len())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, recorder.WriteHAR(buf))
+	// secrets are redacted
+	require.NotContains(t, buf.String(), "XXXXX")
+	require.Contains(t, buf.String(), `"value": "REDACTED"`)
+	services, err := flute.ReadHAR(buf)
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	require.Equal(t, "http://example.com", services[0].Endpoint)
+	require.Len(t, services[0].Routes, 1)
+	route := services[0].Routes[0]
+	require.Equal(t, http.MethodPost, route.Matcher.Method)
+	require.Equal(t, "/users", route.Matcher.Path)
+	require.Equal(t, `{"name": "foo"}`, route.Matcher.BodyString)
+	require.Equal(t, http.StatusCreated, route.Response.Base.StatusCode)
+	require.Equal(t, `{"id": 10, "name": "foo"}`, route.Response.BodyString)
+}
+
+func TestReadHAR_repeatedRequests(t *testing.T) {
+	recorder := &flute.Recorder{}
+	statuses := []string{"pending", "running", "done"}
+	n := 0
+	transport := flute.Transport{
+		T:        t,
+		Recorder: recorder,
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Name: "get the job",
+						Matcher: flute.Matcher{
+							Method: http.MethodGet,
+							Path:   "/jobs/10",
+						},
+						Response: flute.Response{
+							Response: func(req *http.Request) (*http.Response, error) {
+								status := statuses[n]
+								n++
+								return &http.Response{
+									Request:    req,
+									StatusCode: http.StatusOK,
+									Header:     http.Header{},
+									Body:       io.NopCloser(strings.NewReader(status)),
+								}, nil
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	getStatus := func(t *testing.T, transport http.RoundTripper) string {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/jobs/10", nil)
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+	for _, status := range statuses {
+		require.Equal(t, status, getStatus(t, transport))
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, recorder.WriteHAR(buf))
+	services, err := flute.ReadHAR(buf)
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	require.Len(t, services[0].Routes, 1)
+	require.NoError(t, services[0].Validate())
+
+	replay := flute.Transport{
+		T:        t,
+		Services: services,
+	}
+	// the responses are returned in the recorded order and then the last response is repeated
+	for _, exp := range []string{"pending", "running", "done", "done"} {
+		require.Equal(t, exp, getStatus(t, replay))
+	}
+}
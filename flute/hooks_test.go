@@ -0,0 +1,81 @@
+package flute_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestTransport_Hooks(t *testing.T) {
+	var calls []string
+	transport := flute.Transport{
+		T: t,
+		Hooks: flute.Hooks{
+			BeforeMatch: []func(req *http.Request) error{
+				func(req *http.Request) error {
+					calls = append(calls, "before match: "+req.URL.Path)
+					req.Header.Set("X-Request-Id", "10")
+					return nil
+				},
+			},
+			AfterMatch: []func(req *http.Request, service flute.Service, route flute.Route) error{
+				func(req *http.Request, service flute.Service, route flute.Route) error {
+					calls = append(calls, "after match: "+route.Name)
+					return nil
+				},
+			},
+			AfterResponse: []func(req *http.Request, resp *http.Response, service flute.Service, route flute.Route) error{
+				func(req *http.Request, resp *http.Response, service flute.Service, route flute.Route) error {
+					calls = append(calls, "after response: "+route.Name)
+					resp.Header.Set("X-Request-Id", req.Header.Get("X-Request-Id"))
+					return nil
+				},
+			},
+		},
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Name: "get a user",
+						Matcher: flute.Matcher{
+							PartOfHeader: http.Header{
+								"X-Request-Id": nil,
+							},
+						},
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusOK,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users/10", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "10", resp.Header.Get("X-Request-Id"))
+	// the hooks don't change the caller's request
+	require.Empty(t, req.Header.Get("X-Request-Id"))
+	require.Same(t, req, resp.Request)
+	require.Equal(t, []string{
+		"before match: /users/10",
+		"after match: get a user",
+		"after response: get a user",
+	}, calls)
+
+	errHook := errors.New("hook error")
+	transport.Hooks.AfterMatch = append(transport.Hooks.AfterMatch, func(req *http.Request, service flute.Service, route flute.Route) error {
+		return errHook
+	})
+	_, err = transport.RoundTrip(req) //nolint:bodyclose
+	require.ErrorIs(t, err, errHook)
+}
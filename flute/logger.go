@@ -0,0 +1,35 @@
+package flute
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// NewTestLogger returns a logger which writes the trace logs of the transport to the test log with t.Log.
+// All levels including debug are written.
+// The logger must not be used after the test completes.
+func NewTestLogger(t testing.TB) *slog.Logger {
+	return slog.New(slog.NewTextHandler(testLogWriter{t: t}, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+}
+
+type testLogWriter struct {
+	t testing.TB
+}
+
+func (w testLogWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// discardLogger is used when Transport.Logger is nil.
+var discardLogger = slog.New(slog.DiscardHandler) //nolint:gochecknoglobals
+
+func (transport Transport) logger() *slog.Logger {
+	if transport.Logger != nil {
+		return transport.Logger
+	}
+	return discardLogger
+}
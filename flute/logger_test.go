@@ -0,0 +1,81 @@
+package flute_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestTransport_Logger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	transport := flute.Transport{
+		T: t,
+		Logger: slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		})),
+		Services: []flute.Service{
+			{
+				Endpoint: "http://api.example.com",
+			},
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Name: "create a user",
+						Matcher: flute.Matcher{
+							Method: http.MethodPost,
+						},
+					},
+					{
+						Name: "get the user 1",
+						Matcher: flute.Matcher{
+							Path: "/users/1",
+						},
+					},
+					{
+						Name: "get a user",
+						Matcher: flute.Matcher{
+							PathTemplate: "/users/{id}",
+						},
+						Response: flute.Response{
+							Base: http.Response{
+								StatusCode: http.StatusOK,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users/10", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	// remove the time
+	logs := regexp.MustCompile(`(?m)^time=\S+ `).ReplaceAllString(buf.String(), "")
+	require.Equal(t, `level=INFO msg="flute: request" method=GET url=http://example.com/users/10
+level=DEBUG msg="flute: the service doesn't match" service=http://api.example.com
+level=DEBUG msg="flute: the route doesn't match" service=http://example.com route="create a user" route_index=0 condition=Method
+level=DEBUG msg="flute: the route doesn't match" service=http://example.com route="get the user 1" route_index=1 condition=Path
+level=INFO msg="flute: the route matches" service=http://example.com route="get a user" route_index=2
+level=INFO msg="flute: response" service=http://example.com route="get a user" status=200
+`, logs)
+}
+
+func TestNewTestLogger(t *testing.T) {
+	transport := flute.Transport{
+		Logger: flute.NewTestLogger(t),
+	}
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users/10", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
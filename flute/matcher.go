@@ -1,6 +1,7 @@
 package flute
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,6 +23,34 @@ func matchPath(req *http.Request, matcher Matcher) (bool, error) {
 	return matcher.Path == "" || matcher.Path == req.URL.Path, nil
 }
 
+func matchPathTemplate(req *http.Request, matcher Matcher) (bool, error) {
+	if matcher.PathTemplate == "" {
+		return true, nil
+	}
+	return matchTemplate(matcher.PathTemplate, req.URL.Path), nil
+}
+
+// matchTemplate returns whether the path matches with the path template.
+func matchTemplate(tpl, path string) bool {
+	tplSegs := strings.Split(tpl, "/")
+	segs := strings.Split(path, "/")
+	if len(tplSegs) != len(segs) {
+		return false
+	}
+	for i, tplSeg := range tplSegs {
+		if strings.HasPrefix(tplSeg, "{") && strings.HasSuffix(tplSeg, "}") {
+			if segs[i] == "" {
+				return false
+			}
+			continue
+		}
+		if tplSeg != segs[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func matchMethod(req *http.Request, matcher Matcher) (bool, error) {
 	return matcher.Method == "" || strings.EqualFold(matcher.Method, req.Method), nil
 }
@@ -34,26 +63,55 @@ func matchQuery(req *http.Request, matcher Matcher) (bool, error) {
 	return matcher.Query == nil || reflect.DeepEqual(matcher.Query, req.URL.Query()), nil
 }
 
-var matchFuncs = [...]matchFunc{ //nolint:gochecknoglobals
-	matchPath, matchMethod, matchBodyString, matchBodyJSON, matchBodyJSONString,
-	matchPartOfHeader, matchHeader, matchPartOfQuery, matchQuery,
+// matchFuncs are the conditions of Matcher.
+// The name is the field of Matcher, which is logged when the condition rejects the request.
+var matchFuncs = [...]struct { //nolint:gochecknoglobals
+	name  string
+	match matchFunc
+}{
+	{"Path", matchPath},
+	{"PathTemplate", matchPathTemplate},
+	{"Method", matchMethod},
+	{"BodyString", matchBodyString},
+	{"BodyJSON", matchBodyJSON},
+	{"BodyJSONString", matchBodyJSONString},
+	{"BodyFile", matchBodyFile},
+	{"PartOfHeader", matchPartOfHeader},
+	{"Header", matchHeader},
+	{"PartOfQuery", matchPartOfQuery},
+	{"Query", matchQuery},
+	{"PartOfCookie", matchPartOfCookie},
+	{"Auth", matchAuth},
 }
 
 // isMatch returns whether the request matches with the matcher.
 // If the matcher has multiple conditions, IsMatch returns true if the request meets all conditions.
 func isMatch(req *http.Request, matcher Matcher) (bool, error) {
-	for _, match := range matchFuncs {
-		if f, err := match(req, matcher); err != nil || !f {
-			return f, err
+	f, _, err := matchCondition(req, matcher)
+	return f, err
+}
+
+// matchCondition is the same as isMatch but also returns the name of the condition which rejects the request.
+// Some conditions read the request body, so the body is restored before each condition.
+func matchCondition(req *http.Request, matcher Matcher) (bool, string, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return false, "", err
+	}
+	for _, mf := range matchFuncs {
+		resetRequestBody(req, body)
+		if f, err := mf.match(req, matcher); err != nil || !f {
+			return f, mf.name, err
 		}
 	}
 	if matcher.Match != nil {
+		resetRequestBody(req, body)
 		f, err := matcher.Match(req)
 		if err != nil || !f {
-			return f, err
+			return f, "Match", err
 		}
 	}
-	return true, nil
+	return true, "", nil
 }
 
 func matchPartOfHeader(req *http.Request, matcher Matcher) (bool, error) {
@@ -131,3 +189,45 @@ func matchBodyJSON(req *http.Request, matcher Matcher) (bool, error) {
 	}
 	return dataeq.JSON.Equal(b, matcher.BodyJSON)
 }
+
+func matchBodyFile(req *http.Request, matcher Matcher) (bool, error) {
+	if matcher.BodyFile == "" {
+		return true, nil
+	}
+	expected, contentType, err := readBodyFile(matcher.FS, matcher.BodyFile)
+	if err != nil {
+		return false, err
+	}
+	if req.Body == nil {
+		return false, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the request body: %w", err)
+	}
+	if isJSONContentType(contentType) {
+		return dataeq.JSON.Equal(b, expected)
+	}
+	return bytes.Equal(expected, b), nil
+}
+
+// mismatchError is the reason why the condition rejects the request.
+// It isn't a failure to check the condition, so it is output only to the trace log.
+type mismatchError struct {
+	err error
+}
+
+func (e *mismatchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *mismatchError) Unwrap() error {
+	return e.err
+}
+
+func matchAuth(req *http.Request, matcher Matcher) (bool, error) {
+	if err := verifyAuth(req, matcher.BasicAuth, matcher.BearerToken, matcher.HMAC, matcher.AWSSigV4); err != nil {
+		return false, &mismatchError{err: err}
+	}
+	return true, nil
+}
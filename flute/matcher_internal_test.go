@@ -170,6 +170,7 @@ func Test_isMatch(t *testing.T) { //nolint:funlen
 		},
 		{
 			title: "match function doesn't match",
+			req:   &http.Request{},
 			matcher: Matcher{
 				Match: func(req *http.Request) (bool, error) {
 					return false, nil
@@ -303,6 +304,7 @@ func Benchmark_isMatch(b *testing.B) { //nolint:funlen
 		},
 		{
 			title: "match function doesn't match",
+			req:   &http.Request{},
 			matcher: Matcher{
 				Match: func(req *http.Request) (bool, error) {
 					return false, nil
@@ -838,3 +840,46 @@ func Benchmark_matchBodyJSON(b *testing.B) {
 		})
 	}
 }
+
+func Test_matchTemplate(t *testing.T) {
+	data := []struct {
+		title string
+		tpl   string
+		path  string
+		exp   bool
+	}{
+		{
+			title: "no variable",
+			tpl:   "/users",
+			path:  "/users",
+			exp:   true,
+		},
+		{
+			title: "variable",
+			tpl:   "/users/{id}/repos",
+			path:  "/users/10/repos",
+			exp:   true,
+		},
+		{
+			title: "empty segment",
+			tpl:   "/users/{id}",
+			path:  "/users/",
+		},
+		{
+			title: "different number of segments",
+			tpl:   "/users/{id}",
+			path:  "/users/10/repos",
+		},
+		{
+			title: "different segment",
+			tpl:   "/users/{id}",
+			path:  "/groups/10",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			require.Equal(t, d.exp, matchTemplate(d.tpl, d.path))
+		})
+	}
+}
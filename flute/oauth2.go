@@ -0,0 +1,330 @@
+package flute
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	defaultOAuth2TokenPath           = "/oauth/token"
+	defaultOAuth2AccessTokenLifetime = time.Hour
+)
+
+type (
+	// OAuth2Server is a mock of the token endpoint of an OAuth2 authorization server.
+	// OAuth2Server supports the client credentials, refresh token, and authorization code grants.
+	// Other routes can require the access token issued by OAuth2Server with Match, Test, and UnauthorizedRoute.
+	// OAuth2Server must not be copied after first use.
+	OAuth2Server struct {
+		// Endpoint is the endpoint of the service such as "https://auth.example.com".
+		Endpoint string
+		// TokenPath is the path of the token endpoint. The default is "/oauth/token".
+		TokenPath string
+		// Clients is the pairs of the client ID and secret.
+		// If Clients is nil, any client is accepted.
+		Clients map[string]string
+		// AccessTokenLifetime is the lifetime of the access token. The default is one hour.
+		AccessTokenLifetime time.Duration
+		// RefreshTokenLifetime is the lifetime of the refresh token.
+		// If RefreshTokenLifetime is zero, the refresh token doesn't expire.
+		RefreshTokenLifetime time.Duration
+		// Scope is the scope of the issued token. If Scope is empty, the requested scope is granted.
+		Scope string
+		// If Error is set, the token endpoint returns the error.
+		Error *OAuth2Error
+		// Clock is used to check the token expiry. If Clock is nil, the real clock is used.
+		Clock Clock
+		// Reporter reports the failure of Test. Set the same Reporter as Transport.Reporter.
+		// If Reporter is nil, TestifyReporter is used.
+		Reporter Reporter
+
+		mu            sync.Mutex
+		accessTokens  map[string]oauth2Token
+		refreshTokens map[string]oauth2Token
+		codes         map[string]oauth2Code
+	}
+
+	// OAuth2Error is the error response of the token endpoint.
+	OAuth2Error struct {
+		// StatusCode is the HTTP status code. The default is 400.
+		StatusCode int
+		// Code is the error code such as "invalid_grant".
+		Code string
+		// Description is the human-readable description of the error.
+		Description string
+	}
+
+	oauth2Token struct {
+		clientID  string
+		scope     string
+		expiresAt time.Time
+	}
+
+	oauth2Code struct {
+		clientID    string
+		redirectURI string
+		scope       string
+	}
+
+	oauth2TokenResponse struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+		Scope        string `json:"scope,omitempty"`
+	}
+
+	oauth2ErrorResponse struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description,omitempty"`
+	}
+)
+
+// TokenURL returns the URL of the token endpoint.
+func (srv *OAuth2Server) TokenURL() string {
+	return srv.Endpoint + srv.tokenPath()
+}
+
+func (srv *OAuth2Server) tokenPath() string {
+	if srv.TokenPath == "" {
+		return defaultOAuth2TokenPath
+	}
+	return srv.TokenPath
+}
+
+// Service returns the service which has the token endpoint.
+func (srv *OAuth2Server) Service() Service {
+	return Service{
+		Endpoint: srv.Endpoint,
+		Routes: []Route{
+			{
+				Name: "OAuth2 token endpoint",
+				Matcher: Matcher{
+					Method: http.MethodPost,
+					Path:   srv.tokenPath(),
+				},
+				Response: Response{
+					Response: srv.token,
+				},
+			},
+		},
+	}
+}
+
+// IssueAuthorizationCode issues the authorization code for the authorization code grant.
+// The code can be exchanged to the token only once.
+func (srv *OAuth2Server) IssueAuthorizationCode(clientID, redirectURI, scope string) string {
+	code := newOAuth2Token()
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.codes == nil {
+		srv.codes = map[string]oauth2Code{}
+	}
+	srv.codes[code] = oauth2Code{
+		clientID:    clientID,
+		redirectURI: redirectURI,
+		scope:       scope,
+	}
+	return code
+}
+
+// ValidToken returns whether the request has the valid access token issued by the server.
+func (srv *OAuth2Server) ValidToken(req *http.Request) bool {
+	token, ok := bearerToken(req)
+	if !ok {
+		return false
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	t, ok := srv.accessTokens[token]
+	return ok && srv.now().Before(t.expiresAt)
+}
+
+// Match is used as Matcher.Match to match requests with the valid access token.
+func (srv *OAuth2Server) Match(req *http.Request) (bool, error) {
+	return srv.ValidToken(req), nil
+}
+
+// Test is used as Tester.Test to require the valid access token.
+func (srv *OAuth2Server) Test(t testing.TB, req *http.Request, service Service, route Route) {
+	t.Helper()
+	if !srv.ValidToken(req) {
+		getReporter(srv.Reporter).Fail(t, makeMsg("the request should have the valid access token issued by the OAuth2 server", service.Endpoint, route.Name))
+	}
+}
+
+// UnauthorizedRoute returns the route which returns 401 to requests without the valid access token.
+// Put the route before other routes of the service to require the access token.
+func (srv *OAuth2Server) UnauthorizedRoute() Route {
+	return Route{
+		Name: "OAuth2 unauthorized",
+		Matcher: Matcher{
+			Match: func(req *http.Request) (bool, error) {
+				return !srv.ValidToken(req), nil
+			},
+		},
+		Response: Response{
+			Base: http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header: http.Header{
+					"Www-Authenticate": []string{`Bearer error="invalid_token"`},
+				},
+			},
+			BodyJSON: oauth2ErrorResponse{
+				Error: "invalid_token",
+			},
+		},
+	}
+}
+
+func (srv *OAuth2Server) now() time.Time {
+	return getClock(srv.Clock).Now()
+}
+
+// token handles the token request.
+func (srv *OAuth2Server) token(req *http.Request) (*http.Response, error) {
+	if srv.Error != nil {
+		return srv.Error.response(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return oauth2Err(req, http.StatusBadRequest, "invalid_request", "the request body isn't form-encoded")
+	}
+	clientID, ok := srv.authenticateClient(req, form)
+	if !ok {
+		return oauth2Err(req, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	switch grantType := form.Get("grant_type"); grantType {
+	case "client_credentials":
+		return srv.issue(req, clientID, srv.grantedScope(form.Get("scope")), false)
+	case "authorization_code":
+		code, ok := srv.codes[form.Get("code")]
+		if !ok || code.clientID != clientID || code.redirectURI != form.Get("redirect_uri") {
+			return oauth2Err(req, http.StatusBadRequest, "invalid_grant", "the authorization code is invalid")
+		}
+		delete(srv.codes, form.Get("code"))
+		return srv.issue(req, clientID, srv.grantedScope(code.scope), true)
+	case "refresh_token":
+		refreshToken := form.Get("refresh_token")
+		token, ok := srv.refreshTokens[refreshToken]
+		if !ok || token.clientID != clientID || (!token.expiresAt.IsZero() && !srv.now().Before(token.expiresAt)) {
+			return oauth2Err(req, http.StatusBadRequest, "invalid_grant", "the refresh token is invalid")
+		}
+		delete(srv.refreshTokens, refreshToken)
+		return srv.issue(req, clientID, token.scope, true)
+	default:
+		return oauth2Err(req, http.StatusBadRequest, "unsupported_grant_type", "the grant type isn't supported: "+grantType)
+	}
+}
+
+// authenticateClient authenticates the client with Basic authentication or the request body.
+func (srv *OAuth2Server) authenticateClient(req *http.Request, form url.Values) (string, bool) {
+	clientID, secret, ok := req.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID = form.Get("client_id")
+		secret = form.Get("client_secret")
+	}
+	if srv.Clients == nil {
+		return clientID, true
+	}
+	s, ok := srv.Clients[clientID]
+	return clientID, ok && s == secret
+}
+
+func (srv *OAuth2Server) grantedScope(requested string) string {
+	if srv.Scope != "" {
+		return srv.Scope
+	}
+	return requested
+}
+
+// issue issues the access token and the refresh token.
+// srv.mu must be locked.
+func (srv *OAuth2Server) issue(req *http.Request, clientID, scope string, withRefreshToken bool) (*http.Response, error) {
+	lifetime := srv.AccessTokenLifetime
+	if lifetime == 0 {
+		lifetime = defaultOAuth2AccessTokenLifetime
+	}
+	now := srv.now()
+	if srv.accessTokens == nil {
+		srv.accessTokens = map[string]oauth2Token{}
+	}
+	resp := oauth2TokenResponse{
+		AccessToken: newOAuth2Token(),
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(lifetime.Seconds()),
+		Scope:       scope,
+	}
+	srv.accessTokens[resp.AccessToken] = oauth2Token{
+		clientID:  clientID,
+		scope:     scope,
+		expiresAt: now.Add(lifetime),
+	}
+	if withRefreshToken {
+		if srv.refreshTokens == nil {
+			srv.refreshTokens = map[string]oauth2Token{}
+		}
+		resp.RefreshToken = newOAuth2Token()
+		token := oauth2Token{
+			clientID: clientID,
+			scope:    scope,
+		}
+		if srv.RefreshTokenLifetime != 0 {
+			token.expiresAt = now.Add(srv.RefreshTokenLifetime)
+		}
+		srv.refreshTokens[resp.RefreshToken] = token
+	}
+	return oauth2JSON(req, http.StatusOK, resp)
+}
+
+func (e *OAuth2Error) response(req *http.Request) (*http.Response, error) {
+	status := e.StatusCode
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	return oauth2Err(req, status, e.Code, e.Description)
+}
+
+func oauth2Err(req *http.Request, status int, code, description string) (*http.Response, error) {
+	return oauth2JSON(req, status, oauth2ErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
+
+func oauth2JSON(req *http.Request, status int, body any) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(req, status, http.Header{
+		"Cache-Control": []string{"no-store"},
+	}, string(b), "application/json"), nil
+}
+
+func newOAuth2Token() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,179 @@
+package flute_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	return clock.now
+}
+
+func (clock *fakeClock) Add(d time.Duration) {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	clock.now = clock.now.Add(d)
+}
+
+func newOAuth2Client(t *testing.T, srv *flute.OAuth2Server) *http.Client {
+	t.Helper()
+	return &http.Client{
+		Transport: flute.Transport{
+			T: t,
+			Services: []flute.Service{
+				srv.Service(),
+				{
+					Endpoint: "http://api.example.com",
+					Routes: []flute.Route{
+						srv.UnauthorizedRoute(),
+						{
+							Name: "get a user",
+							Tester: flute.Tester{
+								Test: srv.Test,
+							},
+							Response: flute.Response{
+								Base: http.Response{
+									StatusCode: http.StatusOK,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getUser(ctx context.Context, t *testing.T, client *http.Client) int {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://api.example.com/users/10", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestOAuth2Server_clientCredentials(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	srv := &flute.OAuth2Server{
+		Endpoint: "http://auth.example.com",
+		Clients: map[string]string{
+			"client": "secret",
+		},
+		AccessTokenLifetime: time.Minute,
+		Clock:               clock,
+	}
+	client := newOAuth2Client(t, srv)
+	ctx := context.WithValue(t.Context(), oauth2.HTTPClient, client)
+
+	conf := &clientcredentials.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		TokenURL:     srv.TokenURL(),
+		Scopes:       []string{"read"},
+	}
+	token, err := conf.Token(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "read", token.Extra("scope"))
+	require.Equal(t, http.StatusOK, getUser(ctx, t, conf.Client(ctx)))
+
+	clock.Add(2 * time.Minute)
+	require.Equal(t, http.StatusUnauthorized, getUser(ctx, t, &http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(token),
+			Base:   client.Transport,
+		},
+	}))
+
+	conf.ClientSecret = "wrong"
+	_, err = conf.Token(ctx)
+	require.Error(t, err)
+}
+
+func TestOAuth2Server_authorizationCode(t *testing.T) {
+	srv := &flute.OAuth2Server{
+		Endpoint: "http://auth.example.com",
+	}
+	client := newOAuth2Client(t, srv)
+	ctx := context.WithValue(t.Context(), oauth2.HTTPClient, client)
+	conf := &oauth2.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/callback",
+		Endpoint: oauth2.Endpoint{
+			TokenURL: srv.TokenURL(),
+		},
+	}
+	code := srv.IssueAuthorizationCode("client", "http://localhost/callback", "read")
+	token, err := conf.Exchange(ctx, code)
+	require.NoError(t, err)
+	require.NotEmpty(t, token.RefreshToken)
+
+	// the code can be used only once
+	_, err = conf.Exchange(ctx, code)
+	var retrieveErr *oauth2.RetrieveError
+	require.ErrorAs(t, err, &retrieveErr)
+	require.Equal(t, "invalid_grant", retrieveErr.ErrorCode)
+
+	// refresh the token
+	token.Expiry = time.Now().Add(-time.Minute)
+	newToken, err := conf.TokenSource(ctx, token).Token()
+	require.NoError(t, err)
+	require.NotEqual(t, token.AccessToken, newToken.AccessToken)
+	require.Equal(t, http.StatusOK, getUser(ctx, t, conf.Client(ctx, newToken)))
+
+	// the old refresh token is revoked
+	_, err = conf.TokenSource(ctx, token).Token()
+	require.ErrorAs(t, err, &retrieveErr)
+	require.Equal(t, "invalid_grant", retrieveErr.ErrorCode)
+}
+
+func TestOAuth2Server_error(t *testing.T) {
+	srv := &flute.OAuth2Server{
+		Endpoint: "http://auth.example.com",
+		Error: &flute.OAuth2Error{
+			Code:        "invalid_grant",
+			Description: "the grant is revoked",
+		},
+	}
+	ctx := context.WithValue(t.Context(), oauth2.HTTPClient, newOAuth2Client(t, srv))
+	conf := &clientcredentials.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		TokenURL:     srv.TokenURL(),
+	}
+	_, err := conf.Token(ctx)
+	var retrieveErr *oauth2.RetrieveError
+	require.ErrorAs(t, err, &retrieveErr)
+	require.Equal(t, "invalid_grant", retrieveErr.ErrorCode)
+	require.Equal(t, "the grant is revoked", retrieveErr.ErrorDescription)
+}
+
+func TestOAuth2Server_Test(t *testing.T) {
+	rep := &recordReporter{}
+	srv := &flute.OAuth2Server{
+		Endpoint: "http://auth.example.com",
+		Reporter: rep,
+	}
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://api.example.com/users/10", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer invalid")
+	srv.Test(t, req, flute.Service{Endpoint: "http://api.example.com"}, flute.Route{Name: "get a user"})
+	require.Len(t, rep.msgs, 1)
+}
@@ -0,0 +1,144 @@
+package flute
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// OpenAPI is an OpenAPI 3 document.
+// If Service.OpenAPI is set, the requests and the mocked responses of the service
+// are validated against the document.
+type OpenAPI struct {
+	doc      *openapi3.T
+	router   routers.Router
+	basePath string
+}
+
+// LoadOpenAPI reads an OpenAPI 3 document from a local file.
+// The document's servers are used only to get the base path,
+// because the scheme and host are given by Service.Endpoint.
+func LoadOpenAPI(path string) (*OpenAPI, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load the OpenAPI document %s: %w", path, err)
+	}
+	return newOpenAPI(doc)
+}
+
+func newOpenAPI(doc *openapi3.T) (*OpenAPI, error) {
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("validate the OpenAPI document: %w", err)
+	}
+	basePath := ""
+	if len(doc.Servers) != 0 {
+		u, err := url.Parse(doc.Servers[0].URL)
+		if err != nil {
+			return nil, fmt.Errorf("parse the server URL of the OpenAPI document: %w", err)
+		}
+		basePath = strings.TrimSuffix(u.Path, "/")
+	}
+	// Copy the document to match the request path regardless of the host.
+	d := *doc
+	d.Servers = nil
+	router, err := legacy.NewRouter(&d)
+	if err != nil {
+		return nil, fmt.Errorf("create a router of the OpenAPI document: %w", err)
+	}
+	return &OpenAPI{
+		doc:      doc,
+		router:   router,
+		basePath: basePath,
+	}, nil
+}
+
+// Doc returns the OpenAPI document.
+func (oa *OpenAPI) Doc() *openapi3.T {
+	return oa.doc
+}
+
+// validateRequest validates the request against the document.
+// validateRequest returns the validation input, which is required to validate the response.
+func (oa *OpenAPI) validateRequest(req *http.Request) (*openapi3filter.RequestValidationInput, error) {
+	r := req.Clone(req.Context())
+	p := req.URL.Path
+	if oa.basePath != "" {
+		if !strings.HasPrefix(p, oa.basePath) {
+			return nil, fmt.Errorf("the request path %s doesn't start with the base path %s", p, oa.basePath)
+		}
+		p = strings.TrimPrefix(p, oa.basePath)
+	}
+	r.URL.Path = p
+	r.URL.RawPath = ""
+	route, pathParams, err := oa.router.FindRoute(r)
+	if err != nil {
+		return nil, fmt.Errorf("find the operation of the request %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	input := &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+			SkipSettingDefaults: true,
+			MultiError:          true,
+		},
+	}
+	if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+		return input, fmt.Errorf("the request doesn't conform to the OpenAPI document: %w", err)
+	}
+	return input, nil
+}
+
+// validateResponse validates the response against the document.
+func (oa *OpenAPI) validateResponse(input *openapi3filter.RequestValidationInput, resp *http.Response) error {
+	if input == nil || resp == nil {
+		return nil
+	}
+	// The body of the streamed response isn't validated not to block the stream.
+	stream := isStreamResponse(resp)
+	var body []byte
+	if !stream {
+		b, err := readResponseBody(resp)
+		if err != nil {
+			return err
+		}
+		body = b
+	}
+	status := resp.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if err := openapi3filter.ValidateResponse(input.Request.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 resp.Header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			ExcludeResponseBody:   stream,
+			MultiError:            true,
+		},
+	}); err != nil {
+		return fmt.Errorf("the response doesn't conform to the OpenAPI document: %w", err)
+	}
+	return nil
+}
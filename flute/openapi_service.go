@@ -0,0 +1,229 @@
+package flute
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxExampleDepth is the maximum depth of the schema to synthesize the example.
+// This prevents the infinite recursion of the recursive schema.
+const maxExampleDepth = 10
+
+// NewServiceFromOpenAPI returns the service which has one route per operation of the OpenAPI document.
+// The route matches the request by the method and the path template,
+// and returns the successful response whose body is the example in the document.
+// If the document has no example, the response body is synthesized from the schema.
+// For media types other than JSON, scalar examples are formatted as text and object examples make the body empty.
+// The requests and responses of the service are validated against the document.
+//
+// To override the route per test, prepend routes to Service.Routes.
+func NewServiceFromOpenAPI(endpoint string, oa *OpenAPI) (Service, error) {
+	service := Service{
+		Endpoint: endpoint,
+		OpenAPI:  oa,
+	}
+	if oa.doc.Paths == nil {
+		return service, nil
+	}
+	for _, path := range oa.doc.Paths.InMatchingOrder() {
+		pathItem := oa.doc.Paths.Value(path)
+		operations := pathItem.Operations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		slices.Sort(methods)
+		for _, method := range methods {
+			service.Routes = append(service.Routes, newRouteFromOperation(oa.basePath+path, method, operations[method]))
+		}
+	}
+	return service, nil
+}
+
+func newRouteFromOperation(path, method string, op *openapi3.Operation) Route {
+	name := op.OperationID
+	if name == "" {
+		name = method + " " + path
+	}
+	route := Route{
+		Name: name,
+		Matcher: Matcher{
+			Method:       method,
+			PathTemplate: path,
+		},
+	}
+	status, resp := successResponse(op.Responses)
+	route.Response.Base.StatusCode = status
+	if resp == nil {
+		return route
+	}
+	mime, mediaType := preferredMediaType(resp.Content)
+	if mediaType == nil {
+		return route
+	}
+	route.Response.Base.Header = http.Header{
+		"Content-Type": []string{mime},
+	}
+	body := exampleOfMediaType(mediaType)
+	if body == nil {
+		return route
+	}
+	if !strings.Contains(mime, "json") {
+		route.Response.BodyString = formatExample(body)
+		return route
+	}
+	route.Response.BodyJSON = body
+	return route
+}
+
+// formatExample returns the example as the body of the media type other than JSON.
+// Scalars such as numbers and booleans are formatted as they are.
+// Objects and arrays can't be formatted without knowing the media type, so the body is empty.
+func formatExample(example any) string {
+	switch example.(type) {
+	case map[string]any, []any:
+		return ""
+	default:
+		return fmt.Sprint(example)
+	}
+}
+
+// successResponse returns the status code and the response which the route returns.
+// The lowest 2xx response is preferred, and then the default response is used.
+func successResponse(responses *openapi3.Responses) (int, *openapi3.Response) {
+	if responses == nil {
+		return http.StatusOK, nil
+	}
+	keys := responses.Keys()
+	slices.Sort(keys)
+	for _, key := range keys {
+		status, err := strconv.Atoi(key)
+		if err != nil || status < 200 || status >= 300 {
+			continue
+		}
+		return status, responses.Value(key).Value
+	}
+	if ref := responses.Default(); ref != nil {
+		return http.StatusOK, ref.Value
+	}
+	for _, key := range keys {
+		if status, err := strconv.Atoi(key); err == nil {
+			return status, responses.Value(key).Value
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// preferredMediaType returns the media type of the response.
+// application/json is preferred.
+func preferredMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	if mediaType := content.Get("application/json"); mediaType != nil {
+		return "application/json", mediaType
+	}
+	mimes := make([]string, 0, len(content))
+	for mime := range content {
+		mimes = append(mimes, mime)
+	}
+	slices.Sort(mimes)
+	for _, mime := range mimes {
+		return mime, content[mime]
+	}
+	return "", nil
+}
+
+func exampleOfMediaType(mediaType *openapi3.MediaType) any {
+	if mediaType.Example != nil {
+		return mediaType.Example
+	}
+	names := make([]string, 0, len(mediaType.Examples))
+	for name := range mediaType.Examples {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if ref := mediaType.Examples[name]; ref != nil && ref.Value != nil && ref.Value.Value != nil {
+			return ref.Value.Value
+		}
+	}
+	if mediaType.Schema == nil {
+		return nil
+	}
+	return synthesizeExample(mediaType.Schema.Value, 0)
+}
+
+// synthesizeExample returns the example value which conforms to the schema.
+func synthesizeExample(schema *openapi3.Schema, depth int) any { //nolint:cyclop
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if len(schema.Enum) != 0 {
+		return schema.Enum[0]
+	}
+	if len(schema.AllOf) != 0 {
+		obj := map[string]any{}
+		for _, ref := range schema.AllOf {
+			if m, ok := synthesizeExample(ref.Value, depth+1).(map[string]any); ok {
+				for k, v := range m {
+					obj[k] = v
+				}
+			}
+		}
+		return obj
+	}
+	if len(schema.OneOf) != 0 {
+		return synthesizeExample(schema.OneOf[0].Value, depth+1)
+	}
+	if len(schema.AnyOf) != 0 {
+		return synthesizeExample(schema.AnyOf[0].Value, depth+1)
+	}
+	switch {
+	case schema.Type.Is(openapi3.TypeObject) || len(schema.Properties) != 0:
+		obj := make(map[string]any, len(schema.Properties))
+		for name, ref := range schema.Properties {
+			obj[name] = synthesizeExample(ref.Value, depth+1)
+		}
+		return obj
+	case schema.Type.Is(openapi3.TypeArray):
+		if schema.Items == nil {
+			return []any{}
+		}
+		return []any{synthesizeExample(schema.Items.Value, depth+1)}
+	case schema.Type.Is(openapi3.TypeString):
+		return exampleString(schema.Format)
+	case schema.Type.Is(openapi3.TypeInteger), schema.Type.Is(openapi3.TypeNumber):
+		if schema.Min != nil {
+			return *schema.Min
+		}
+		return 0
+	case schema.Type.Is(openapi3.TypeBoolean):
+		return false
+	}
+	return nil
+}
+
+func exampleString(format string) string {
+	switch format {
+	case "date-time":
+		return "2006-01-02T15:04:05Z"
+	case "date":
+		return "2006-01-02"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri":
+		return "http://example.com"
+	}
+	return "string"
+}
@@ -0,0 +1,127 @@
+package flute_test
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestNewServiceFromOpenAPI(t *testing.T) {
+	oa, err := flute.LoadOpenAPI("testdata/openapi.yaml")
+	require.NoError(t, err)
+	service, err := flute.NewServiceFromOpenAPI("http://example.com", oa)
+	require.NoError(t, err)
+	require.Len(t, service.Routes, 2)
+
+	data := []struct {
+		title      string
+		method     string
+		path       string
+		body       string
+		statusCode int
+		exp        string
+	}{
+		{
+			title:      "example",
+			method:     http.MethodPost,
+			path:       "/v1/users",
+			body:       `{"name": "foo", "email": "foo@example.com"}`,
+			statusCode: http.StatusCreated,
+			exp:        `{"id": 10, "name": "foo", "email": "foo@example.com"}`,
+		},
+		{
+			title:      "synthesized from the schema",
+			method:     http.MethodGet,
+			path:       "/v1/users/10",
+			statusCode: http.StatusOK,
+			exp:        `{"id": 0, "name": "string", "email": "string"}`,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			rep := &recordReporter{}
+			transport := flute.Transport{
+				T:        t,
+				Reporter: rep,
+				Services: []flute.Service{service},
+			}
+			resp, err := transport.RoundTrip(&http.Request{
+				URL: &url.URL{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   d.path,
+				},
+				Method: d.method,
+				Header: http.Header{
+					"Content-Type": []string{"application/json"},
+				},
+				Body: io.NopCloser(strings.NewReader(d.body)),
+			})
+			require.NoError(t, err)
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, d.statusCode, resp.StatusCode)
+			require.JSONEq(t, d.exp, string(b))
+			require.Empty(t, rep.msgs)
+		})
+	}
+}
+
+func TestNewServiceFromOpenAPI_text(t *testing.T) {
+	oa, err := flute.LoadOpenAPI("testdata/openapi_text.yaml")
+	require.NoError(t, err)
+	service, err := flute.NewServiceFromOpenAPI("http://example.com", oa)
+	require.NoError(t, err)
+
+	data := []struct {
+		title string
+		path  string
+		exp   string
+	}{
+		{
+			title: "integer synthesized from the schema",
+			path:  "/count",
+			exp:   "0",
+		},
+		{
+			title: "boolean example",
+			path:  "/enabled",
+			exp:   "true",
+		},
+		{
+			title: "object example isn't formatted",
+			path:  "/users",
+			exp:   "",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			transport := flute.Transport{
+				T: t,
+				// kin-openapi validates the text/plain body as a string, so the integer body is reported
+				Reporter: &recordReporter{},
+				Services: []flute.Service{service},
+			}
+			resp, err := transport.RoundTrip(&http.Request{
+				URL: &url.URL{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   d.path,
+				},
+				Method: http.MethodGet,
+			})
+			require.NoError(t, err)
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, d.exp, string(b))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package flute_test
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestLoadOpenAPI(t *testing.T) {
+	_, err := flute.LoadOpenAPI("testdata/openapi.yaml")
+	require.NoError(t, err)
+	_, err = flute.LoadOpenAPI("testdata/not_found.yaml")
+	require.Error(t, err)
+}
+
+func TestTransport_RoundTrip_openAPI(t *testing.T) { //nolint:funlen
+	oa, err := flute.LoadOpenAPI("testdata/openapi.yaml")
+	require.NoError(t, err)
+	data := []struct {
+		title    string
+		method   string
+		path     string
+		body     string
+		response flute.Response
+		failures int
+	}{
+		{
+			title:  "valid",
+			method: http.MethodPost,
+			path:   "/v1/users",
+			body:   `{"name": "foo", "email": "foo@example.com"}`,
+			response: flute.Response{
+				Base: http.Response{
+					StatusCode: http.StatusCreated,
+					Header: http.Header{
+						"Content-Type": []string{"application/json"},
+					},
+				},
+				BodyString: `{"id": 10, "name": "foo", "email": "foo@example.com"}`,
+			},
+		},
+		{
+			title:  "invalid request body",
+			method: http.MethodPost,
+			path:   "/v1/users",
+			body:   `{"name": "foo"}`,
+			response: flute.Response{
+				Base: http.Response{
+					StatusCode: http.StatusCreated,
+					Header: http.Header{
+						"Content-Type": []string{"application/json"},
+					},
+				},
+				BodyString: `{"id": 10, "name": "foo", "email": "foo@example.com"}`,
+			},
+			failures: 1,
+		},
+		{
+			title:  "invalid response body",
+			method: http.MethodGet,
+			path:   "/v1/users/10",
+			response: flute.Response{
+				Base: http.Response{
+					StatusCode: http.StatusOK,
+					Header: http.Header{
+						"Content-Type": []string{"application/json"},
+					},
+				},
+				BodyString: `{"id": "10"}`,
+			},
+			failures: 1,
+		},
+		{
+			title:  "undeclared status",
+			method: http.MethodGet,
+			path:   "/v1/users/10",
+			response: flute.Response{
+				Base: http.Response{
+					StatusCode: http.StatusInternalServerError,
+				},
+			},
+			failures: 1,
+		},
+		{
+			title:    "undeclared path",
+			method:   http.MethodGet,
+			path:     "/v1/groups",
+			failures: 1,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			rep := &recordReporter{}
+			transport := flute.Transport{
+				T:        t,
+				Reporter: rep,
+				Services: []flute.Service{
+					{
+						Endpoint: "http://example.com",
+						OpenAPI:  oa,
+						Routes: []flute.Route{
+							{
+								Name: d.title,
+								Tester: flute.Tester{
+									BodyString: d.body,
+								},
+								Response: d.response,
+							},
+						},
+					},
+				},
+			}
+			resp, err := transport.RoundTrip(&http.Request{
+				URL: &url.URL{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   d.path,
+				},
+				Method: d.method,
+				Header: http.Header{
+					"Content-Type": []string{"application/json"},
+				},
+				Body: io.NopCloser(strings.NewReader(d.body)),
+			})
+			require.NoError(t, err)
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, d.response.BodyString, string(b))
+			require.Len(t, rep.msgs, d.failures, rep.msgs)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package flute
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// override is a layer of the routes added by DynamicTransport.Override.
+type override struct {
+	t        testing.TB
+	services []Service
+}
+
+// Derive returns a copy of the transport for the test t such as a subtest of t.Run.
+// The services override the transport's services and everything else is inherited.
+// A route whose name is the same as an inherited route of the same endpoint replaces the inherited route.
+// Other routes are added before the inherited routes, so they take precedence.
+// A service whose endpoint isn't found is added.
+// The transport isn't changed.
+func (transport Transport) Derive(t testing.TB, services ...Service) Transport {
+	transport.T = t
+	transport.Services = mergeServices(transport.Services, services)
+	return transport
+}
+
+// Override overrides the routes while the test t runs, in the same way as Transport.Derive.
+// The override is removed at the cleanup of t, so it fits a subtest sharing the http.Client with the parent test.
+// While the override is active, the test failures are reported to t.
+// Overrides can be nested; the latest override takes precedence.
+//
+// All requests of the DynamicTransport see the override, so Override isn't safe for parallel subtests with t.Parallel.
+// If the override of a test which isn't t or the ancestor of t is active, Override fails t.
+// For parallel subtests, use Transport.Derive and an http.Client per subtest instead.
+func (dt *DynamicTransport) Override(t testing.TB, services ...Service) {
+	o := &override{
+		t:        t,
+		services: cloneServices(services),
+	}
+	dt.mu.Lock()
+	for _, active := range dt.overrides {
+		if !isSameOrSubtest(t, active.t) {
+			rep := dt.transport.reporter()
+			dt.mu.Unlock()
+			rep.FailNow(t, fmt.Sprintf("the override of %s is active. DynamicTransport.Override can't be used in parallel subtests. Use Transport.Derive instead", active.t.Name()))
+			return
+		}
+	}
+	dt.overrides = append(dt.overrides, o)
+	dt.applyOverrides()
+	dt.mu.Unlock()
+	t.Cleanup(func() {
+		dt.mu.Lock()
+		defer dt.mu.Unlock()
+		dt.overrides = slices.DeleteFunc(dt.overrides, func(x *override) bool {
+			return x == o
+		})
+		dt.applyOverrides()
+	})
+}
+
+// isSameOrSubtest returns whether t is parent or a subtest of parent.
+func isSameOrSubtest(t, parent testing.TB) bool {
+	return t == parent || strings.HasPrefix(t.Name(), parent.Name()+"/")
+}
+
+// applyOverrides updates the current transport.
+// dt.mu must be locked.
+func (dt *DynamicTransport) applyOverrides() {
+	current := dt.transport
+	for _, o := range dt.overrides {
+		current = current.Derive(o.t, o.services...)
+	}
+	dt.current = current
+}
+
+// mergeServices returns the services which the overrides are merged into.
+// base isn't changed.
+func mergeServices(base, overrides []Service) []Service {
+	services := cloneServices(base)
+	for _, o := range overrides {
+		i := indexOfService(services, o.Endpoint)
+		if i == -1 {
+			o.Routes = slices.Clone(o.Routes)
+			services = append(services, o)
+			continue
+		}
+		service := services[i]
+		if o.OpenAPI != nil {
+			service.OpenAPI = o.OpenAPI
+		}
+		if o.RateLimiter != nil {
+			service.RateLimiter = o.RateLimiter
+		}
+		var added []Route
+		for _, route := range o.Routes {
+			j := -1
+			if route.Name != "" {
+				j = slices.IndexFunc(service.Routes, func(r Route) bool {
+					return r.Name == route.Name
+				})
+			}
+			if j == -1 {
+				added = append(added, route)
+				continue
+			}
+			service.Routes[j] = route
+		}
+		service.Routes = append(added, service.Routes...)
+		services[i] = service
+	}
+	return services
+}
@@ -0,0 +1,149 @@
+package flute_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestTransport_Derive(t *testing.T) {
+	base := flute.Transport{
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					statusRoute("get a user", http.StatusOK),
+				},
+			},
+		},
+	}
+	data := []struct {
+		title      string
+		services   []flute.Service
+		statusCode int
+	}{
+		{
+			title:      "inherit",
+			statusCode: http.StatusOK,
+		},
+		{
+			title: "replace the route of the same name",
+			services: []flute.Service{
+				{
+					Endpoint: "http://example.com",
+					Routes: []flute.Route{
+						statusRoute("get a user", http.StatusServiceUnavailable),
+					},
+				},
+			},
+			statusCode: http.StatusServiceUnavailable,
+		},
+		{
+			title: "added routes take precedence",
+			services: []flute.Service{
+				{
+					Endpoint: "http://example.com",
+					Routes: []flute.Route{
+						statusRoute("get a user with an error", http.StatusInternalServerError),
+					},
+				},
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			title: "add the service",
+			services: []flute.Service{
+				{
+					Endpoint: "http://api.example.com",
+					Routes: []flute.Route{
+						statusRoute("get a user", http.StatusInternalServerError),
+					},
+				},
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			transport := base.Derive(t, d.services...)
+			require.Equal(t, t, transport.T)
+			require.Equal(t, d.statusCode, getStatusCode(t, transport))
+		})
+	}
+	// the base transport isn't changed
+	require.Len(t, base.Services, 1)
+	require.Equal(t, []flute.Route{statusRoute("get a user", http.StatusOK)}, base.Services[0].Routes)
+}
+
+func TestDynamicTransport_Override(t *testing.T) {
+	transport := flute.NewDynamicTransport(flute.Transport{
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					statusRoute("get a user", http.StatusOK),
+				},
+			},
+		},
+	})
+	client := &http.Client{Transport: transport}
+	t.Run("override", func(t *testing.T) {
+		transport.Override(t, flute.Service{
+			Endpoint: "http://example.com",
+			Routes: []flute.Route{
+				statusRoute("get a user", http.StatusServiceUnavailable),
+			},
+		})
+		require.Equal(t, http.StatusServiceUnavailable, getStatusCode(t, client.Transport))
+		t.Run("nested override", func(t *testing.T) {
+			transport.Override(t, flute.Service{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					statusRoute("get a user", http.StatusNotFound),
+				},
+			})
+			require.Equal(t, http.StatusNotFound, getStatusCode(t, client.Transport))
+		})
+		require.Equal(t, http.StatusServiceUnavailable, getStatusCode(t, client.Transport))
+		// changes of the base routes are kept while overriding
+		transport.AddRoute("http://api.example.com", statusRoute("list users", http.StatusOK))
+	})
+	require.Equal(t, http.StatusOK, getStatusCode(t, client.Transport))
+	require.Len(t, transport.Services(), 2)
+	require.Nil(t, transport.Transport().T)
+}
+
+func TestDynamicTransport_Override_conflict(t *testing.T) {
+	rep := &recordReporter{}
+	transport := flute.NewDynamicTransport(flute.Transport{
+		Reporter: rep,
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					statusRoute("get a user", http.StatusOK),
+				},
+			},
+		},
+	})
+	parent := t
+	t.Run("override", func(t *testing.T) {
+		transport.Override(t, flute.Service{
+			Endpoint: "http://example.com",
+			Routes: []flute.Route{
+				statusRoute("get a user", http.StatusServiceUnavailable),
+			},
+		})
+		// the override of a test which isn't a subtest of the active override's test, such as a parallel sibling test, fails
+		transport.Override(parent, flute.Service{
+			Endpoint: "http://example.com",
+			Routes: []flute.Route{
+				statusRoute("get a user", http.StatusNotFound),
+			},
+		})
+		require.Len(t, rep.msgs, 1)
+		require.Equal(t, http.StatusServiceUnavailable, getStatusCode(t, transport))
+	})
+}
@@ -0,0 +1,153 @@
+package flute
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// RateLimiter limits the requests to the route or the service.
+	// If the request isn't allowed, the transport returns 429 Too Many Requests
+	// with Retry-After and X-RateLimit-* headers.
+	RateLimiter interface {
+		// Allow consumes the quota and returns whether the request is allowed.
+		Allow(req *http.Request) RateLimitStatus
+	}
+
+	// RateLimitStatus is the result of RateLimiter.Allow.
+	RateLimitStatus struct {
+		Allowed bool
+		// Limit is the maximum number of requests.
+		Limit int
+		// Remaining is the number of remaining requests.
+		Remaining int
+		// Reset is the time when the quota is reset.
+		Reset time.Time
+		// RetryAfter is the duration until the next request is allowed.
+		RetryAfter time.Duration
+	}
+
+	// FixedWindowRateLimiter allows Limit requests per Window.
+	// FixedWindowRateLimiter must not be copied after first use.
+	FixedWindowRateLimiter struct {
+		Limit  int
+		Window time.Duration
+		// Clock is the clock. If Clock is nil, the real clock is used.
+		Clock Clock
+
+		mu          sync.Mutex
+		windowStart time.Time
+		count       int
+	}
+
+	// TokenBucketRateLimiter is the token bucket algorithm rate limiter.
+	// The bucket has Capacity tokens at first, and a token is added every Interval.
+	// TokenBucketRateLimiter must not be copied after first use.
+	TokenBucketRateLimiter struct {
+		Capacity int
+		Interval time.Duration
+		// Clock is the clock. If Clock is nil, the real clock is used.
+		Clock Clock
+
+		mu          sync.Mutex
+		initialized bool
+		tokens      float64
+		last        time.Time
+	}
+)
+
+// Allow implements RateLimiter.
+func (limiter *FixedWindowRateLimiter) Allow(_ *http.Request) RateLimitStatus {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	now := getClock(limiter.Clock).Now()
+	if limiter.windowStart.IsZero() || !now.Before(limiter.windowStart.Add(limiter.Window)) {
+		limiter.windowStart = now
+		limiter.count = 0
+	}
+	reset := limiter.windowStart.Add(limiter.Window)
+	status := RateLimitStatus{
+		Limit: limiter.Limit,
+		Reset: reset,
+	}
+	if limiter.count >= limiter.Limit {
+		status.RetryAfter = reset.Sub(now)
+		return status
+	}
+	limiter.count++
+	status.Allowed = true
+	status.Remaining = limiter.Limit - limiter.count
+	return status
+}
+
+// Allow implements RateLimiter.
+func (limiter *TokenBucketRateLimiter) Allow(_ *http.Request) RateLimitStatus {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	now := getClock(limiter.Clock).Now()
+	if !limiter.initialized {
+		limiter.initialized = true
+		limiter.tokens = float64(limiter.Capacity)
+		limiter.last = now
+	}
+	if elapsed := now.Sub(limiter.last); elapsed > 0 && limiter.Interval > 0 {
+		limiter.tokens = math.Min(float64(limiter.Capacity), limiter.tokens+float64(elapsed)/float64(limiter.Interval))
+		limiter.last = now
+	}
+	status := RateLimitStatus{
+		Limit: limiter.Capacity,
+	}
+	if limiter.tokens < 1 {
+		status.RetryAfter = time.Duration((1 - limiter.tokens) * float64(limiter.Interval))
+		status.Reset = now.Add(status.RetryAfter)
+		return status
+	}
+	limiter.tokens--
+	status.Allowed = true
+	status.Remaining = int(limiter.tokens)
+	status.Reset = now.Add(time.Duration((float64(limiter.Capacity) - limiter.tokens) * float64(limiter.Interval)))
+	return status
+}
+
+// setHeader sets the X-RateLimit-* headers.
+func (status RateLimitStatus) setHeader(header http.Header) {
+	header.Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(ceilUnix(status.Reset), 10))
+}
+
+func ceilUnix(t time.Time) int64 {
+	if t.Nanosecond() == 0 {
+		return t.Unix()
+	}
+	return t.Unix() + 1
+}
+
+// checkRateLimit checks the rate limits of the service and the route.
+// If a request is allowed, checkRateLimit returns the status of the route's limiter if any.
+func checkRateLimit(req *http.Request, service Service, route Route) (RateLimitStatus, bool) {
+	var status RateLimitStatus
+	found := false
+	for _, limiter := range []RateLimiter{service.RateLimiter, route.RateLimiter} {
+		if limiter == nil {
+			continue
+		}
+		status = limiter.Allow(req)
+		found = true
+		if !status.Allowed {
+			return status, true
+		}
+	}
+	return status, found
+}
+
+func tooManyRequestsResponse(req *http.Request, status RateLimitStatus) *http.Response {
+	header := http.Header{
+		"Retry-After": []string{strconv.FormatInt(int64(math.Ceil(status.RetryAfter.Seconds())), 10)},
+	}
+	status.setHeader(header)
+	return newResponse(req, http.StatusTooManyRequests, header, `{"message": "rate limit exceeded"}`, "application/json")
+}
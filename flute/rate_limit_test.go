@@ -0,0 +1,115 @@
+package flute_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+type rateLimitStep struct {
+	advance    time.Duration
+	statusCode int
+	retryAfter string
+	remaining  string
+}
+
+func testRateLimit(t *testing.T, clock *fakeClock, service flute.Service, steps []rateLimitStep) {
+	t.Helper()
+	transport := flute.Transport{
+		T:        t,
+		Services: []flute.Service{service},
+	}
+	for i, step := range steps {
+		clock.Add(step.advance)
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users", nil)
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, step.statusCode, resp.StatusCode, i)
+		require.Equal(t, step.retryAfter, resp.Header.Get("Retry-After"), i)
+		require.Equal(t, step.remaining, resp.Header.Get("X-RateLimit-Remaining"), i)
+	}
+}
+
+func TestFixedWindowRateLimiter(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	testRateLimit(t, clock, flute.Service{
+		Endpoint: "http://example.com",
+		RateLimiter: &flute.FixedWindowRateLimiter{
+			Limit:  2,
+			Window: time.Minute,
+			Clock:  clock,
+		},
+		Routes: []flute.Route{
+			{
+				Response: flute.Response{
+					Base: http.Response{
+						StatusCode: http.StatusOK,
+					},
+				},
+			},
+		},
+	}, []rateLimitStep{
+		{statusCode: http.StatusOK, remaining: "1"},
+		{statusCode: http.StatusOK, remaining: "0"},
+		{advance: 15 * time.Second, statusCode: http.StatusTooManyRequests, retryAfter: "45", remaining: "0"},
+		{advance: 45 * time.Second, statusCode: http.StatusOK, remaining: "1"},
+	})
+}
+
+func TestTokenBucketRateLimiter(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	testRateLimit(t, clock, flute.Service{
+		Endpoint: "http://example.com",
+		Routes: []flute.Route{
+			{
+				RateLimiter: &flute.TokenBucketRateLimiter{
+					Capacity: 1,
+					Interval: 10 * time.Second,
+					Clock:    clock,
+				},
+				Response: flute.Response{
+					Base: http.Response{
+						StatusCode: http.StatusOK,
+					},
+				},
+			},
+		},
+	}, []rateLimitStep{
+		{statusCode: http.StatusOK, remaining: "0"},
+		{statusCode: http.StatusTooManyRequests, retryAfter: "10", remaining: "0"},
+		{advance: 5 * time.Second, statusCode: http.StatusTooManyRequests, retryAfter: "5", remaining: "0"},
+		{advance: 5 * time.Second, statusCode: http.StatusOK, remaining: "0"},
+	})
+}
+
+func TestFixedWindowRateLimiter_openAPI(t *testing.T) {
+	oa, err := flute.LoadOpenAPI("testdata/openapi.yaml")
+	require.NoError(t, err)
+	service, err := flute.NewServiceFromOpenAPI("http://example.com", oa)
+	require.NoError(t, err)
+	service.RateLimiter = &flute.FixedWindowRateLimiter{
+		Limit:  1,
+		Window: time.Minute,
+		Clock:  &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	rep := &recordReporter{}
+	transport := flute.Transport{
+		T:        t,
+		Reporter: rep,
+		Services: []flute.Service{service},
+	}
+	for _, statusCode := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/v1/users/10", nil)
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, statusCode, resp.StatusCode)
+	}
+	require.Empty(t, rep.msgs)
+}
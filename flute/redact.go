@@ -0,0 +1,259 @@
+package flute
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/suzuki-shunsuke/go-dataeq/v2/dataeq"
+)
+
+const redactedValue = "REDACTED"
+
+// DefaultRedaction is the Redaction which is used if Transport.Redaction is nil.
+var DefaultRedaction = Redaction{ //nolint:gochecknoglobals
+	Headers: []string{
+		"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key",
+	},
+	QueryKeys: []string{
+		"access_token", "refresh_token", "client_secret", "password", "api_key", "token",
+	},
+	JSONPaths: []string{
+		"**.access_token", "**.refresh_token", "**.client_secret", "**.password", "**.api_key", "**.token",
+	},
+}
+
+// Redaction is the rules to replace secrets with "REDACTED" in the failure messages, curl commands, trace logs and HAR recordings.
+// The values which Tester asserts are compared as they are and redacted only in the failure messages.
+// Failures which Tester.Test reports by itself aren't redacted.
+type Redaction struct {
+	// Headers are the names of the request and response headers. They are case-insensitive.
+	Headers []string
+	// QueryKeys are the keys of the query parameters and the form parameters of the request body.
+	QueryKeys []string
+	// JSONPaths are the paths of the values in JSON bodies, which are keys separated by ".".
+	// "*" matches any key or array index and "**" matches any number of keys and array indexes.
+	// For example, "user.password" matches the password of the root's user and "**.password" matches the password at any depth.
+	JSONPaths []string
+}
+
+func (transport Transport) redaction() *Redaction {
+	if transport.Redaction != nil {
+		return transport.Redaction
+	}
+	return &DefaultRedaction
+}
+
+func (redaction *Redaction) isSecretHeader(name string) bool {
+	return slices.ContainsFunc(redaction.Headers, func(h string) bool {
+		return strings.EqualFold(h, name)
+	})
+}
+
+func (redaction *Redaction) isSecretQueryKey(key string) bool {
+	return slices.Contains(redaction.QueryKeys, key)
+}
+
+// header returns the copy of the header whose secret values are redacted.
+func (redaction *Redaction) header(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+	h := header.Clone()
+	for k, v := range h {
+		if !redaction.isSecretHeader(k) {
+			continue
+		}
+		for i := range v {
+			v[i] = redactedValue
+		}
+	}
+	return h
+}
+
+// url returns the copy of the URL whose secret query values are redacted.
+// The order of the query parameters is kept.
+func (redaction *Redaction) url(u *url.URL) *url.URL {
+	if u == nil || u.RawQuery == "" {
+		return u
+	}
+	c := *u
+	c.RawQuery = redaction.rawQuery(u.RawQuery)
+	return &c
+}
+
+func (redaction *Redaction) rawQuery(rawQuery string) string {
+	pairs := strings.Split(rawQuery, "&")
+	for i, pair := range pairs {
+		k, _, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			continue
+		}
+		if redaction.isSecretQueryKey(key) {
+			pairs[i] = k + "=" + redactedValue
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// query returns the copy of the query whose secret values are redacted.
+func (redaction *Redaction) query(query url.Values) url.Values {
+	q := make(url.Values, len(query))
+	for k, v := range query {
+		v = slices.Clone(v)
+		if redaction.isSecretQueryKey(k) {
+			for i := range v {
+				v[i] = redactedValue
+			}
+		}
+		q[k] = v
+	}
+	return q
+}
+
+// body returns the body whose secret values are redacted.
+// JSON bodies are redacted with JSONPaths and form bodies are redacted with QueryKeys.
+// If nothing is redacted, body is returned as it is.
+func (redaction *Redaction) body(body []byte, contentType string) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	if len(redaction.JSONPaths) != 0 && json.Valid(body) {
+		return redaction.jsonBody(body)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" {
+		return []byte(redaction.rawQuery(string(body)))
+	}
+	return body
+}
+
+func (redaction *Redaction) jsonBody(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return body
+	}
+	changed := false
+	for _, p := range redaction.JSONPaths {
+		var c bool
+		v, c = redactJSON(v, strings.Split(p, "."))
+		changed = changed || c
+	}
+	if !changed {
+		return body
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return b
+}
+
+// redactJSON replaces the values at the path with "REDACTED" and returns whether the values are replaced.
+func redactJSON(v any, path []string) (any, bool) {
+	if len(path) == 0 {
+		return redactedValue, true
+	}
+	seg, rest := path[0], path[1:]
+	changed := false
+	if seg == "**" {
+		// "**" matches zero key
+		v, changed = redactJSON(v, rest)
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			var c bool
+			switch seg {
+			case "**":
+				val[k], c = redactJSON(child, path)
+			case "*", k:
+				val[k], c = redactJSON(child, rest)
+			}
+			changed = changed || c
+		}
+	case []any:
+		for i, child := range val {
+			var c bool
+			switch seg {
+			case "**":
+				val[i], c = redactJSON(child, path)
+			case "*", strconv.Itoa(i):
+				val[i], c = redactJSON(child, rest)
+			}
+			changed = changed || c
+		}
+	}
+	return v, changed
+}
+
+// value returns the copy of the asserted value whose secrets are redacted.
+// Headers, queries, and strings as the request body are redacted and other values are returned as they are.
+func (redaction *Redaction) value(v any, contentType string) any {
+	switch val := v.(type) {
+	case http.Header:
+		return redaction.header(val)
+	case url.Values:
+		if val == nil {
+			return val
+		}
+		return redaction.query(val)
+	case string:
+		return string(redaction.body([]byte(val), contentType))
+	}
+	return v
+}
+
+// redactReporter is the Reporter which redacts the values of the assertions in the failure messages.
+// The values are compared as they are, so the secrets are still asserted.
+type redactReporter struct {
+	Reporter
+
+	redaction *Redaction
+	// contentType is the Content-Type of the request body
+	contentType string
+}
+
+func newRedactReporter(rep Reporter, redaction *Redaction, contentType string) redactReporter {
+	return redactReporter{
+		Reporter:    rep,
+		redaction:   redaction,
+		contentType: contentType,
+	}
+}
+
+func (rep redactReporter) Equal(t testing.TB, expected, actual any, msg string) bool {
+	if reflect.DeepEqual(expected, actual) {
+		return true
+	}
+	expected = rep.redaction.value(expected, rep.contentType)
+	actual = rep.redaction.value(actual, rep.contentType)
+	if reflect.DeepEqual(expected, actual) {
+		return rep.Fail(t, msg+"\nthe values differ only in the redacted parts")
+	}
+	return rep.Reporter.Equal(t, expected, actual, msg)
+}
+
+func (rep redactReporter) JSONEq(t testing.TB, expected, actual, msg string) bool {
+	if f, err := dataeq.JSON.Equal([]byte(expected), []byte(actual)); err == nil && f {
+		return true
+	}
+	redactedExpected := string(rep.redaction.body([]byte(expected), rep.contentType))
+	redactedActual := string(rep.redaction.body([]byte(actual), rep.contentType))
+	if redactedExpected != expected || redactedActual != actual {
+		if f, err := dataeq.JSON.Equal([]byte(redactedExpected), []byte(redactedActual)); err == nil && f {
+			return rep.Fail(t, msg+"\nthe values differ only in the redacted parts")
+		}
+	}
+	return rep.Reporter.JSONEq(t, redactedExpected, redactedActual, msg)
+}
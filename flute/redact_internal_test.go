@@ -0,0 +1,74 @@
+package flute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedaction_body(t *testing.T) {
+	data := []struct {
+		title       string
+		paths       []string
+		body        string
+		contentType string
+		exp         string
+	}{
+		{
+			title: "not changed",
+			paths: []string{"password"},
+			body:  `{"name": "foo"}`,
+			exp:   `{"name": "foo"}`,
+		},
+		{
+			title: "root key",
+			paths: []string{"password"},
+			body:  `{"name": "foo", "password": "XXXXX", "user": {"password": "XXXXX"}}`,
+			exp:   `{"name":"foo","password":"REDACTED","user":{"password":"XXXXX"}}`,
+		},
+		{
+			title: "nested key",
+			paths: []string{"user.password"},
+			body:  `{"user": {"password": "XXXXX", "age": 10}}`,
+			exp:   `{"user":{"age":10,"password":"REDACTED"}}`,
+		},
+		{
+			title: "wildcard",
+			paths: []string{"users.*.token"},
+			body:  `{"users": [{"token": "XXXXX"}, {"token": "YYYYY"}]}`,
+			exp:   `{"users":[{"token":"REDACTED"},{"token":"REDACTED"}]}`,
+		},
+		{
+			title: "array index",
+			paths: []string{"1"},
+			body:  `["foo", "XXXXX"]`,
+			exp:   `["foo","REDACTED"]`,
+		},
+		{
+			title: "any depth",
+			paths: []string{"**.token"},
+			body:  `{"token": "XXXXX", "items": [{"auth": {"token": "YYYYY"}}]}`,
+			exp:   `{"items":[{"auth":{"token":"REDACTED"}}],"token":"REDACTED"}`,
+		},
+		{
+			title: "object",
+			paths: []string{"**.credentials"},
+			body:  `{"credentials": {"key": "XXXXX"}}`,
+			exp:   `{"credentials":"REDACTED"}`,
+		},
+		{
+			title: "not JSON",
+			paths: []string{"**.password"},
+			body:  `password=XXXXX`,
+			exp:   `password=XXXXX`,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			redaction := &Redaction{
+				JSONPaths: d.paths,
+			}
+			require.Equal(t, d.exp, string(redaction.body([]byte(d.body), d.contentType)))
+		})
+	}
+}
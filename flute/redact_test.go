@@ -0,0 +1,87 @@
+package flute_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestTransport_RoundTrip_redactTester(t *testing.T) {
+	data := []struct {
+		title  string
+		header http.Header
+		body   string
+		tester flute.Tester
+	}{
+		{
+			title: "header",
+			header: http.Header{
+				"Authorization": []string{"token actual-secret"},
+			},
+			tester: flute.Tester{
+				PartOfHeader: http.Header{
+					"Authorization": []string{"token expected-secret"},
+				},
+			},
+		},
+		{
+			title: "JSON body",
+			header: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			body: `{"name": "bar", "password": "actual-secret"}`,
+			tester: flute.Tester{
+				BodyJSON: map[string]any{"name": "foo", "password": "expected-secret"},
+			},
+		},
+		{
+			title: "the values differ only in the secret",
+			header: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			body: `{"name": "foo", "password": "actual-secret"}`,
+			tester: flute.Tester{
+				BodyJSONString: `{"name": "foo", "password": "expected-secret"}`,
+			},
+		},
+		{
+			title: "query",
+			tester: flute.Tester{
+				Query: map[string][]string{"token": {"expected-secret"}},
+			},
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			tb := &errorfTB{TB: t}
+			transport := flute.Transport{
+				T: tb,
+				Services: []flute.Service{
+					{
+						Endpoint: "http://example.com",
+						Routes: []flute.Route{
+							{
+								Name:   "create a user",
+								Tester: d.tester,
+							},
+						},
+					},
+				},
+			}
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://example.com/users?token=actual-secret", strings.NewReader(d.body))
+			require.NoError(t, err)
+			for k, v := range d.header {
+				req.Header[k] = v
+			}
+			resp, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Len(t, tb.msgs, 1)
+			require.NotContains(t, tb.msgs[0], "actual-secret")
+			require.Contains(t, tb.msgs[0], "REDACTED")
+		})
+	}
+}
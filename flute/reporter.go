@@ -0,0 +1,58 @@
+package flute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// Reporter reports the results of the request's assertions.
+	// If Transport.Reporter is nil, TestifyReporter is used.
+	Reporter interface {
+		// Equal asserts that expected and actual are equal.
+		Equal(t testing.TB, expected, actual any, msg string) bool
+		// JSONEq asserts that expected and actual are equivalent JSON strings.
+		JSONEq(t testing.TB, expected, actual, msg string) bool
+		// Fail reports a failure and continues the test.
+		Fail(t testing.TB, msg string) bool
+		// FailNow reports a failure and stops the test.
+		FailNow(t testing.TB, msg string)
+	}
+
+	// TestifyReporter is the Reporter with testify's assert and require.
+	TestifyReporter struct{}
+)
+
+// Equal implements Reporter.
+func (TestifyReporter) Equal(t testing.TB, expected, actual any, msg string) bool {
+	return assert.Equal(t, expected, actual, msg)
+}
+
+// JSONEq implements Reporter.
+func (TestifyReporter) JSONEq(t testing.TB, expected, actual, msg string) bool {
+	return assert.JSONEq(t, expected, actual, msg)
+}
+
+// Fail implements Reporter.
+func (TestifyReporter) Fail(t testing.TB, msg string) bool {
+	return assert.Fail(t, msg)
+}
+
+// FailNow implements Reporter.
+func (TestifyReporter) FailNow(t testing.TB, msg string) {
+	require.Fail(t, msg)
+}
+
+func (transport Transport) reporter() Reporter {
+	return getReporter(transport.Reporter)
+}
+
+// getReporter returns the reporter. If rep is nil, TestifyReporter is returned.
+func getReporter(rep Reporter) Reporter {
+	if rep == nil {
+		return TestifyReporter{}
+	}
+	return rep
+}
@@ -0,0 +1,110 @@
+package flute_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+type recordReporter struct {
+	msgs []string
+}
+
+func (rep *recordReporter) Equal(t testing.TB, expected, actual any, msg string) bool {
+	if reflect.DeepEqual(expected, actual) {
+		return true
+	}
+	rep.msgs = append(rep.msgs, msg)
+	return false
+}
+
+func (rep *recordReporter) JSONEq(t testing.TB, expected, actual, msg string) bool {
+	var e, a any
+	if json.Unmarshal([]byte(expected), &e) == nil && json.Unmarshal([]byte(actual), &a) == nil && reflect.DeepEqual(e, a) {
+		return true
+	}
+	rep.msgs = append(rep.msgs, msg)
+	return false
+}
+
+func (rep *recordReporter) Fail(t testing.TB, msg string) bool {
+	rep.msgs = append(rep.msgs, msg)
+	return false
+}
+
+func (rep *recordReporter) FailNow(t testing.TB, msg string) {
+	rep.msgs = append(rep.msgs, msg)
+}
+
+func TestTransport_Reporter(t *testing.T) {
+	rep := &recordReporter{}
+	transport := flute.Transport{
+		T:        t,
+		Reporter: rep,
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Name: "get a user",
+						Matcher: flute.Matcher{
+							Method: http.MethodGet,
+						},
+						Tester: flute.Tester{
+							Path: "/users/foo",
+						},
+					},
+				},
+			},
+		},
+	}
+	resp, err := transport.RoundTrip(&http.Request{
+		URL: &url.URL{
+			Scheme: "http",
+			Host:   "example.com",
+			Path:   "/users/bar",
+		},
+		Method: http.MethodGet,
+		Body:   io.NopCloser(strings.NewReader("")),
+	})
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Len(t, rep.msgs, 1)
+	require.Contains(t, rep.msgs[0], "request path should match")
+	require.Contains(t, rep.msgs[0], "curl: curl 'http://example.com/users/bar'")
+}
+
+func BenchmarkTransport_RoundTrip_withTestingB(b *testing.B) {
+	transport := flute.Transport{
+		T: b,
+		Services: []flute.Service{
+			{
+				Endpoint: "http://example.com",
+				Routes: []flute.Route{
+					{
+						Tester: flute.Tester{
+							Method: http.MethodGet,
+						},
+					},
+				},
+			},
+		},
+	}
+	for range b.N {
+		resp, _ := transport.RoundTrip(&http.Request{
+			URL: &url.URL{
+				Scheme: "http",
+				Host:   "example.com",
+			},
+			Method: http.MethodGet,
+		})
+		resp.Body.Close()
+	}
+}
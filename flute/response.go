@@ -1,9 +1,11 @@
 package flute
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -14,6 +16,8 @@ func createHTTPResponse(req *http.Request, resp Response) (*http.Response, error
 	r := resp.Base
 	r.Request = req
 	var body io.ReadCloser
+	var content []byte
+	contentType := ""
 	if resp.BodyJSON != nil {
 		b, err := json.Marshal(resp.BodyJSON)
 		if err != nil {
@@ -22,10 +26,49 @@ func createHTTPResponse(req *http.Request, resp Response) (*http.Response, error
 				StatusCode: http.StatusInternalServerError,
 			}, err
 		}
-		body = io.NopCloser(strings.NewReader(string(b)))
+		content = b
+		contentType = "application/json"
 	}
 	if resp.BodyString != "" {
-		body = io.NopCloser(strings.NewReader(resp.BodyString))
+		content = []byte(resp.BodyString)
+		contentType = http.DetectContentType(content)
+	}
+	if resp.BodyFile != "" {
+		b, ct, err := readBodyFile(resp.FS, resp.BodyFile)
+		if err != nil {
+			return &http.Response{
+				Request:    req,
+				StatusCode: http.StatusInternalServerError,
+			}, err
+		}
+		content = b
+		if ct != "" && r.Header.Get("Content-Type") == "" {
+			r.Header = cloneHeader(r.Header)
+			r.Header.Set("Content-Type", ct)
+		}
+	}
+	if content != nil {
+		body = io.NopCloser(bytes.NewReader(content))
+	}
+	if resp.Cookies != nil {
+		r.Header = cloneHeader(r.Header)
+		for _, cookie := range resp.Cookies {
+			r.Header.Add("Set-Cookie", cookie.String())
+		}
+	}
+	if resp.Events != nil {
+		r.Header = cloneHeader(r.Header)
+		r.Header.Set("Content-Type", "text/event-stream")
+		r.Header.Set("Cache-Control", "no-cache")
+		body = newStreamBody(req.Context(), eventChunks(resp.Events))
+	}
+	if resp.Chunks != nil {
+		body = newStreamBody(req.Context(), seqChunks(resp.Chunks))
+	}
+	isStream := resp.Events != nil || resp.Chunks != nil
+	if isStream {
+		r.ContentLength = -1
+		r.TransferEncoding = []string{"chunked"}
 	}
 	if body == nil {
 		// https://golang.org/pkg/net/http/#Response
@@ -37,5 +80,56 @@ func createHTTPResponse(req *http.Request, resp Response) (*http.Response, error
 	}
 
 	r.Body = body
+	if !resp.NoDefaults {
+		setResponseDefaults(&r, content, contentType, isStream)
+	}
 	return &r, nil
 }
+
+// setResponseDefaults fills the fields which net/http sets but the route leaves empty.
+// contentType is set to the Content-Type header if the header is empty.
+func setResponseDefaults(resp *http.Response, content []byte, contentType string, isStream bool) {
+	if resp.StatusCode == 0 {
+		resp.StatusCode = http.StatusOK
+	}
+	if resp.Status == "" {
+		resp.Status = strconv.Itoa(resp.StatusCode) + " " + http.StatusText(resp.StatusCode)
+	}
+	if resp.Proto == "" {
+		resp.Proto = "HTTP/1.1"
+		resp.ProtoMajor = 1
+		resp.ProtoMinor = 1
+	}
+	if contentType != "" && resp.Header.Get("Content-Type") == "" {
+		resp.Header = cloneHeader(resp.Header)
+		resp.Header.Set("Content-Type", contentType)
+	}
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	if !isStream && resp.ContentLength == 0 {
+		resp.ContentLength = int64(len(content))
+	}
+}
+
+// newResponse returns the response which flute makes itself, such as 429 Too Many Requests of the rate limiter.
+// The fields which net/http sets are filled with setResponseDefaults.
+func newResponse(req *http.Request, statusCode int, header http.Header, body, contentType string) *http.Response {
+	resp := &http.Response{
+		Request:    req,
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+	setResponseDefaults(resp, []byte(body), contentType, false)
+	return resp
+}
+
+// cloneHeader clones the header not to change the route's header.
+// If the header is nil, cloneHeader returns an empty header.
+func cloneHeader(header http.Header) http.Header {
+	if header == nil {
+		return http.Header{}
+	}
+	return header.Clone()
+}
@@ -41,7 +41,16 @@ func Test_createHTTPResponse(t *testing.T) { //nolint:funlen
 				},
 			},
 			exp: &http.Response{
-				Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`)),
+				Status:     "200 OK",
+				StatusCode: http.StatusOK,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header: http.Header{
+					"FOO":          []string{"foo"},
+					"Content-Type": []string{"application/json"},
+				},
+				ContentLength: 13,
 			},
 			body: `{"foo":"bar"}`,
 		},
@@ -60,16 +69,73 @@ func Test_createHTTPResponse(t *testing.T) { //nolint:funlen
 				BodyString: `{"foo":"bar"}`,
 			},
 			exp: &http.Response{
-				Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`)),
+				Status:     "200 OK",
+				StatusCode: http.StatusOK,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header: http.Header{
+					"Content-Type": []string{"text/plain; charset=utf-8"},
+				},
+				ContentLength: 13,
+			},
+			body: `{"foo":"bar"}`,
+		},
+		{
+			title: "no defaults",
+			req:   &http.Request{},
+			resp: Response{
+				Base: http.Response{
+					StatusCode: http.StatusCreated,
+				},
+				BodyJSON: map[string]any{
+					"foo": "bar",
+				},
+				NoDefaults: true,
+			},
+			exp: &http.Response{
+				StatusCode: http.StatusCreated,
 			},
 			body: `{"foo":"bar"}`,
 		},
+		{
+			title: "keep the status code and Content-Type",
+			req:   &http.Request{},
+			resp: Response{
+				Base: http.Response{
+					StatusCode: http.StatusNotFound,
+					Header: http.Header{
+						"Content-Type": []string{"application/problem+json"},
+					},
+				},
+				BodyJSON: map[string]any{
+					"title": "not found",
+				},
+			},
+			exp: &http.Response{
+				Status:     "404 Not Found",
+				StatusCode: http.StatusNotFound,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header: http.Header{
+					"Content-Type": []string{"application/problem+json"},
+				},
+				ContentLength: 21,
+			},
+			body: `{"title":"not found"}`,
+		},
 		{
 			title: "nil request body",
 			req:   &http.Request{},
 			resp:  Response{},
 			exp: &http.Response{
-				Body: io.NopCloser(strings.NewReader("")),
+				Status:     "200 OK",
+				StatusCode: http.StatusOK,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{},
 			},
 		},
 		{
@@ -84,7 +150,6 @@ func Test_createHTTPResponse(t *testing.T) { //nolint:funlen
 				},
 			},
 			exp: &http.Response{
-				Body:       io.NopCloser(strings.NewReader("foo")),
 				StatusCode: http.StatusForbidden,
 			},
 			body: "foo",
@@ -115,6 +180,12 @@ func Test_createHTTPResponse(t *testing.T) { //nolint:funlen
 			require.NotNil(t, resp.Body)
 
 			require.Equal(t, d.exp.StatusCode, resp.StatusCode)
+			require.Equal(t, d.exp.Status, resp.Status)
+			require.Equal(t, d.exp.Proto, resp.Proto)
+			require.Equal(t, d.exp.ProtoMajor, resp.ProtoMajor)
+			require.Equal(t, d.exp.ProtoMinor, resp.ProtoMinor)
+			require.Equal(t, d.exp.Header, resp.Header)
+			require.Equal(t, d.exp.ContentLength, resp.ContentLength)
 			require.Equal(t, d.body, string(b))
 		})
 	}
@@ -191,3 +262,27 @@ func Benchmark_createHTTPResponse(b *testing.B) { //nolint:funlen
 		})
 	}
 }
+
+func Test_newResponse(t *testing.T) {
+	req := &http.Request{}
+	resp := newResponse(req, http.StatusTooManyRequests, http.Header{
+		"Retry-After": []string{"10"},
+	}, `{"message": "rate limit exceeded"}`, "application/json")
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body = nil
+	require.Equal(t, &http.Response{
+		Request:    req,
+		Status:     "429 Too Many Requests",
+		StatusCode: http.StatusTooManyRequests,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type": []string{"application/json"},
+			"Retry-After":  []string{"10"},
+		},
+		ContentLength: int64(len(b)),
+	}, resp)
+	require.JSONEq(t, `{"message": "rate limit exceeded"}`, string(b))
+}
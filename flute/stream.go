@@ -0,0 +1,145 @@
+package flute
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a Server-Sent Event.
+type Event struct {
+	// Delay is the duration to wait before the event is sent.
+	Delay time.Duration
+	// ID is the event ID.
+	ID string
+	// Event is the event type.
+	Event string
+	// Data is the event data. Multi-line data is sent as multiple "data" fields.
+	Data string
+	// Retry is the reconnection time.
+	Retry time.Duration
+}
+
+// String returns the event in the text/event-stream format.
+func (event Event) String() string {
+	buf := &strings.Builder{}
+	if event.ID != "" {
+		buf.WriteString("id: " + event.ID + "\n")
+	}
+	if event.Event != "" {
+		buf.WriteString("event: " + event.Event + "\n")
+	}
+	if event.Retry != 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	for line := range strings.SplitSeq(event.Data, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// ChunksFromChannel returns an iterator which yields chunks received from the channel until it is closed.
+// The channel can be consumed only once, so the route should be requested only once.
+func ChunksFromChannel(ch <-chan []byte) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for chunk := range ch {
+			if !yield(chunk) {
+				return
+			}
+		}
+	}
+}
+
+// eventChunks converts the events to chunks.
+func eventChunks(events []Event) iter.Seq[chunk] {
+	return func(yield func(chunk) bool) {
+		for _, event := range events {
+			if !yield(chunk{delay: event.Delay, data: []byte(event.String())}) {
+				return
+			}
+		}
+	}
+}
+
+func seqChunks(seq iter.Seq[[]byte]) iter.Seq[chunk] {
+	return func(yield func(chunk) bool) {
+		for data := range seq {
+			if !yield(chunk{data: data}) {
+				return
+			}
+		}
+	}
+}
+
+type chunk struct {
+	delay time.Duration
+	data  []byte
+}
+
+// streamBody is the response body which is written by another goroutine over time.
+// The stream stops when the request context is canceled or the body is closed.
+type streamBody struct {
+	*io.PipeReader
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (body *streamBody) Close() error {
+	body.closeOnce.Do(func() {
+		close(body.done)
+	})
+	return body.PipeReader.Close()
+}
+
+func newStreamBody(ctx context.Context, chunks iter.Seq[chunk]) *streamBody {
+	pr, pw := io.Pipe()
+	body := &streamBody{
+		PipeReader: pr,
+		done:       make(chan struct{}),
+	}
+	go func() {
+		// unblock the write when the request context is canceled
+		stop := context.AfterFunc(ctx, func() {
+			pw.CloseWithError(ctx.Err())
+		})
+		defer stop()
+		for c := range chunks {
+			if c.delay > 0 {
+				timer := time.NewTimer(c.delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					pw.CloseWithError(ctx.Err())
+					return
+				case <-body.done:
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(c.data); err != nil {
+				return
+			}
+		}
+		pw.Close()
+	}()
+	return body
+}
+
+// isStreamResponse returns whether the response body is streamed.
+// The body of the streamed response shouldn't be read in advance.
+func isStreamResponse(resp *http.Response) bool {
+	return slices.Contains(resp.TransferEncoding, "chunked")
+}
@@ -0,0 +1,143 @@
+package flute_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestEvent_String(t *testing.T) {
+	data := []struct {
+		title string
+		event flute.Event
+		exp   string
+	}{
+		{
+			title: "data only",
+			event: flute.Event{
+				Data: "hello",
+			},
+			exp: "data: hello\n\n",
+		},
+		{
+			title: "all fields",
+			event: flute.Event{
+				ID:    "1",
+				Event: "message",
+				Data:  "foo\nbar",
+				Retry: time.Second,
+			},
+			exp: "id: 1\nevent: message\nretry: 1000\ndata: foo\ndata: bar\n\n",
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			require.Equal(t, d.exp, d.event.String())
+		})
+	}
+}
+
+func newStreamClient(t *testing.T, resp flute.Response) *http.Client {
+	t.Helper()
+	return &http.Client{
+		Transport: flute.Transport{
+			T: t,
+			Services: []flute.Service{
+				{
+					Endpoint: "http://example.com",
+					Routes: []flute.Route{
+						{
+							Name:     "stream",
+							Response: resp,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestResponse_Events(t *testing.T) {
+	client := newStreamClient(t, flute.Response{
+		Base: http.Response{
+			StatusCode: http.StatusOK,
+		},
+		Events: []flute.Event{
+			{Data: "foo"},
+			{Delay: 10 * time.Millisecond, Event: "done", Data: "bar"},
+		},
+	})
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/events", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Equal(t, int64(-1), resp.ContentLength)
+
+	reader := bufio.NewReader(resp.Body)
+	lines := []string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		lines = append(lines, line)
+	}
+	require.Equal(t, []string{"data: foo\n", "\n", "event: done\n", "data: bar\n", "\n"}, lines)
+}
+
+func TestResponse_Chunks(t *testing.T) {
+	ch := make(chan []byte)
+	client := newStreamClient(t, flute.Response{
+		Chunks: flute.ChunksFromChannel(ch),
+	})
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/chunks", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+
+	buf := make([]byte, 16)
+	for _, c := range []string{"foo", "bar"} {
+		ch <- []byte(c)
+		n, err := resp.Body.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, c, string(buf[:n]))
+	}
+	close(ch)
+	_, err = resp.Body.Read(buf)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestResponse_Events_cancel(t *testing.T) {
+	client := newStreamClient(t, flute.Response{
+		Events: []flute.Event{
+			{Data: "foo"},
+			{Delay: time.Hour, Data: "bar"},
+		},
+	})
+	ctx, cancel := context.WithCancel(t.Context())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/events", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	buf := make([]byte, 64)
+	n, err := resp.Body.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "data: foo\n\n", string(buf[:n]))
+	cancel()
+	_, err = io.ReadAll(resp.Body)
+	require.ErrorIs(t, err, context.Canceled)
+}
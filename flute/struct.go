@@ -1,6 +1,9 @@
 package flute
 
 import (
+	"io/fs"
+	"iter"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"testing"
@@ -11,10 +14,51 @@ type (
 	Transport struct {
 		// Each service's endpoint should be unique.
 		Services []Service
-		// If *testing.T is nil, the transport is a just mock and doesn't run the test.
-		T *testing.T
+		// If T is nil, the transport is a just mock and doesn't run the test.
+		// T accepts *testing.T, *testing.B, *testing.F, and other testing.TB implementations.
+		T testing.TB
+		// Reporter reports the results of the assertions.
+		// If Reporter is nil, TestifyReporter is used.
+		Reporter Reporter
 		// Transport is used when the request doesn't match with any services.
 		Transport http.RoundTripper
+		// UnmatchedPolicy is the policy when no route matches the request and Transport is nil.
+		// The default is UnmatchedFailNow.
+		UnmatchedPolicy UnmatchedPolicy
+		// UnmatchedResponse returns the response when no route matches the request and UnmatchedPolicy is UnmatchedRespond.
+		UnmatchedResponse func(req *http.Request) (*http.Response, error)
+		// If Recorder is set, the requests and the responses are recorded.
+		Recorder *Recorder
+		// Coverage records which routes were hit.
+		// If Coverage is nil, DefaultCoverage is used.
+		Coverage *Coverage
+		// Hooks are called around RoundTrip.
+		Hooks Hooks
+		// If Logger is set, RoundTrip logs the request, each route tried, the condition which rejects the route, and the response.
+		// Rejected routes are logged at the debug level and the others are logged at the info level.
+		// To write the logs to the test log, use NewTestLogger.
+		Logger *slog.Logger
+		// Redaction is the rules to redact secrets in the failure messages, trace logs and recordings.
+		// If Redaction is nil, DefaultRedaction is used. To disable the redaction, set the empty Redaction.
+		Redaction *Redaction
+		// If Chaos is set, failures are injected into the transport randomly.
+		Chaos *Chaos
+		// If CookieChecker is set, RoundTrip checks that the request sends back
+		// the cookies which the previous responses set.
+		CookieChecker *CookieChecker
+	}
+
+	// Hooks are functions called around RoundTrip.
+	// Hooks can mutate the request or the response, log, or fail the test.
+	// The request is the clone of the request passed to RoundTrip, so mutating it doesn't change the caller's request.
+	// If a hook returns an error, RoundTrip returns the error.
+	Hooks struct {
+		// BeforeMatch hooks are called before the request is matched with routes.
+		BeforeMatch []func(req *http.Request) error
+		// AfterMatch hooks are called after the route is matched and before the route's test is run.
+		AfterMatch []func(req *http.Request, service Service, route Route) error
+		// AfterResponse hooks are called after the response of the matched route is built.
+		AfterResponse []func(req *http.Request, resp *http.Response, service Service, route Route) error
 	}
 
 	// Service is a service.
@@ -25,6 +69,11 @@ type (
 		Endpoint string
 		// If the request matches with a route, other routes are ignored.
 		Routes []Route
+		// If OpenAPI is set, the request and the response of the matched route
+		// are validated against the OpenAPI document.
+		OpenAPI *OpenAPI
+		// RateLimiter limits the requests to all routes of the service.
+		RateLimiter RateLimiter
 	}
 
 	// Route is the pair of the macher, tester, and response.
@@ -34,6 +83,8 @@ type (
 		Matcher  Matcher
 		Tester   Tester
 		Response Response
+		// RateLimiter limits the requests to the route.
+		RateLimiter RateLimiter
 	}
 
 	// Matcher has conditions the request matches with the route.
@@ -44,6 +95,9 @@ type (
 		Method string
 		// Path is the request path.
 		Path string
+		// PathTemplate is the request path template such as "/users/{id}".
+		// Each "{...}" segment matches any non-empty path segment.
+		PathTemplate string
 		// PartOfQuery is the request query parameters.
 		PartOfQuery url.Values
 		// Query is the request query parameters.
@@ -54,17 +108,36 @@ type (
 		BodyJSON any
 		// BodyJSONString is a JSON string and compared to the request body as JSON.
 		BodyJSONString string
+		// BodyFile is the path of the file which is compared to the request body.
+		// If FS is set, the file is read from FS. Otherwise, it is read from the local file system.
+		// If the file extension is ".gz", the file is decompressed.
+		// If the file is JSON such as "*.json", it is compared to the request body as JSON.
+		BodyFile string
+		// FS is the file system which BodyFile is read from, such as embed.FS.
+		FS fs.FS
+		// BasicAuth is the credentials of Basic authentication.
+		BasicAuth *BasicAuth
+		// BearerToken is the token of Bearer authentication.
+		BearerToken string
+		// HMAC is the condition of the request signature with HMAC.
+		HMAC *HMACSignature
+		// AWSSigV4 is the credentials to verify the request signed with AWS Signature Version 4.
+		AWSSigV4 *AWSSigV4
 		// PartOfHeader is the request header's conditions.
 		// If the header value is nil, RoundTrip checks whether the key is included in the request header.
 		// Otherwise, RoundTrip also checks whether the value is equal.
 		PartOfHeader http.Header
 		// Header is the request header's conditions.
 		Header http.Header
+		// PartOfCookie is the request cookies' conditions.
+		// If the cookie value is nil, RoundTrip checks whether the cookie is included in the request.
+		// Otherwise, RoundTrip also checks whether the cookie values are equal.
+		PartOfCookie map[string][]string
 	}
 
 	// Tester has the request's tests.
 	Tester struct {
-		Test func(*testing.T, *http.Request, Service, Route)
+		Test func(testing.TB, *http.Request, Service, Route)
 		// Path is the request path.
 		Path string
 		// Path is the request method such as "GET".
@@ -75,12 +148,36 @@ type (
 		BodyJSON any
 		// BodyJSONString is a JSON string and compared to the request body as JSON.
 		BodyJSONString string
+		// BodyFile is the path of the file which is compared to the request body.
+		// If FS is set, the file is read from FS. Otherwise, it is read from the local file system.
+		// If the file extension is ".gz", the file is decompressed.
+		// If the file is JSON such as "*.json", it is compared to the request body as JSON.
+		BodyFile string
+		// FS is the file system which BodyFile is read from, such as embed.FS.
+		FS fs.FS
+		// GoldenFile is the path of the golden file which is compared to the request body.
+		// A relative path is resolved from the testdata directory.
+		// If the request body is JSON, the formatting is normalized before comparison.
+		// Run the test with the -flute.update flag to create or update the golden file.
+		GoldenFile string
+		// BasicAuth is the credentials of Basic authentication.
+		BasicAuth *BasicAuth
+		// BearerToken is the token of Bearer authentication.
+		BearerToken string
+		// HMAC is the condition of the request signature with HMAC.
+		HMAC *HMACSignature
+		// AWSSigV4 is the credentials to verify the request signed with AWS Signature Version 4.
+		AWSSigV4 *AWSSigV4
 		// PartOfHeader is the request header's conditions.
 		// If the header value is nil, RoundTrip checks whether the key is included in the request header.
 		// Otherwise, RoundTrip also checks whether the value is equal.
 		PartOfHeader http.Header
 		// Header is the request header's conditions.
 		Header http.Header
+		// PartOfCookie is the request cookies' conditions.
+		// If the cookie value is nil, RoundTrip checks whether the cookie is included in the request.
+		// Otherwise, RoundTrip also checks whether the cookie values are equal.
+		PartOfCookie map[string][]string
 		// PartOfQuery is the request query parameters.
 		// If the query value is nil, RoundTrip checks whether the key is included in the request query.
 		// Otherwise, RoundTrip also checks whether the value is equal.
@@ -101,5 +198,28 @@ type (
 		// BodyString is the response body.
 		// BodyJSON and BodyString should only be set to one or the other.
 		BodyString string
+		// BodyFile is the path of the file used as the response body.
+		// If FS is set, the file is read from FS. Otherwise, it is read from the local file system.
+		// If the file extension is ".gz", the file is decompressed.
+		// If the Content-Type header isn't set, it is set from the file extension.
+		BodyFile string
+		// FS is the file system which BodyFile is read from, such as embed.FS.
+		FS fs.FS
+		// Events are Server-Sent Events streamed as the response body.
+		// If Events is set, the Content-Type header is "text/event-stream"
+		// and each event is sent after the event's Delay.
+		Events []Event
+		// Cookies are added to the response as Set-Cookie headers.
+		Cookies []*http.Cookie
+		// Chunks streams the response body with chunked transfer encoding.
+		// Each chunk is sent when the iterator yields it.
+		// To stream chunks from a channel, use ChunksFromChannel.
+		Chunks iter.Seq[[]byte]
+		// By default, the fields which net/http sets are filled if they are empty.
+		// StatusCode defaults to 200, Status to the status text, Proto to HTTP/1.1,
+		// Header to an empty header, ContentLength to the body length,
+		// and the Content-Type header to "application/json" for BodyJSON and the sniffed type for BodyString.
+		// If NoDefaults is true, the response is returned as it is, which is useful to test malformed responses.
+		NoDefaults bool
 	}
 )
@@ -5,44 +5,50 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
-
-	"github.com/stretchr/testify/assert"
 )
 
-type testFunc func(t *testing.T, req *http.Request, service Service, route Route)
+type testFunc func(t testing.TB, rep Reporter, req *http.Request, service Service, route Route)
 
 var testFuncs = [...]testFunc{ //nolint:gochecknoglobals
 	testPath, testMethod, testBodyString, testBodyJSON,
-	testBodyJSONString, testPartOfHeader, testHeader, testPartOfQuery,
-	testQuery,
+	testBodyJSONString, testBodyFile, testGoldenFile, testPartOfHeader, testHeader, testPartOfQuery,
+	testQuery, testPartOfCookie, testAuth,
 }
 
-func testHeader(t *testing.T, req *http.Request, service Service, route Route) {
+func testHeader(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
 	if route.Tester.Header == nil {
 		return
 	}
-	assert.Equal(
+	rep.Equal(
 		t, route.Tester.Header, req.Header,
 		makeMsg("request header should match", service.Endpoint, route.Name))
 }
 
-func testQuery(t *testing.T, req *http.Request, service Service, route Route) {
+func testQuery(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
 	if route.Tester.Query == nil {
 		return
 	}
-	assert.Equal(
+	rep.Equal(
 		t, route.Tester.Query, req.URL.Query(),
 		makeMsg("request query parameter should match", service.Endpoint, route.Name))
 }
 
-func testRequest(t *testing.T, req *http.Request, service Service, route Route) {
+func testRequest(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
+	// each test reads the request body, so the body is restored before each test
+	body, err := readRequestBody(req)
+	if err != nil {
+		rep.Fail(t, makeMsg(err.Error(), service.Endpoint, route.Name))
+	}
 	for _, fn := range testFuncs {
-		fn(t, req, service, route)
+		resetRequestBody(req, body)
+		fn(t, rep, req, service, route)
 	}
 	tester := route.Tester
 	if tester.Test != nil {
+		resetRequestBody(req, body)
 		tester.Test(t, req, service, route)
 	}
 }
@@ -53,105 +59,105 @@ service: %s
 request name: %s`, msg, srv, reqName)
 }
 
-func testBodyString(t *testing.T, req *http.Request, service Service, route Route) {
+func testBodyString(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
 	if route.Tester.BodyString == "" {
 		return
 	}
 
 	if req.Body == nil {
-		assert.Equal(
+		rep.Equal(
 			t, route.Tester.BodyString, "",
 			makeMsg("request body should match", service.Endpoint, route.Name))
 		return
 	}
 	b, err := io.ReadAll(req.Body)
 	if err != nil {
-		assert.Fail(
+		rep.Fail(
 			t, makeMsg(
 				fmt.Sprintf("failed to read the request body: %v", err),
 				service.Endpoint, route.Name))
 		return
 	}
-	assert.Equalf(
+	rep.Equal(
 		t, route.Tester.BodyString, string(b),
 		makeMsg("request body should match", service.Endpoint, route.Name))
 }
 
-func testPath(t *testing.T, req *http.Request, service Service, route Route) {
+func testPath(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
 	if route.Tester.Path == "" {
 		return
 	}
-	assert.Equal(
+	rep.Equal(
 		t, route.Tester.Path, req.URL.Path,
 		makeMsg("request path should match", service.Endpoint, route.Name))
 }
 
-func testMethod(t *testing.T, req *http.Request, service Service, route Route) {
+func testMethod(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
 	if route.Tester.Method == "" {
 		return
 	}
 
-	assert.Equal(
+	rep.Equal(
 		t, strings.ToUpper(route.Tester.Method), strings.ToUpper(req.Method),
 		makeMsg("request method should match", service.Endpoint, route.Name))
 }
 
-func testBodyJSON(t *testing.T, req *http.Request, service Service, route Route) {
+func testBodyJSON(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
 	if route.Tester.BodyJSON == nil {
 		return
 	}
 
 	if req.Body == nil {
-		assert.Equal(
+		rep.Equal(
 			t, route.Tester.BodyJSON, nil,
 			makeMsg("request body should match", service.Endpoint, route.Name))
 		return
 	}
 	b, err := io.ReadAll(req.Body)
 	if err != nil {
-		assert.Fail(
+		rep.Fail(
 			t, makeMsg(
 				fmt.Sprintf("failed to read the request body: %v", err), service.Endpoint, route.Name))
 		return
 	}
 	c, err := json.Marshal(route.Tester.BodyJSON)
 	if err != nil {
-		assert.Fail(
+		rep.Fail(
 			t, makeMsg(
 				fmt.Sprintf("failed to parse route.Tester.bodyJSON as JSON: %v", err),
 				service.Endpoint, route.Name))
 		return
 	}
-	assert.JSONEqf(
+	rep.JSONEq(
 		t, string(c), string(b),
 		makeMsg("request body should match", service.Endpoint, route.Name))
 }
 
-func testBodyJSONString(t *testing.T, req *http.Request, service Service, route Route) {
+func testBodyJSONString(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
 	if route.Tester.BodyJSONString == "" {
 		return
 	}
 
 	if req.Body == nil {
-		assert.Equal(
+		rep.Equal(
 			t, route.Tester.BodyString, "",
 			makeMsg("request body should match", service.Endpoint, route.Name))
 		return
 	}
 	b, err := io.ReadAll(req.Body)
 	if err != nil {
-		assert.Fail(
+		rep.Fail(
 			t, makeMsg(
 				fmt.Sprintf("failed to read the request body: %v", err),
 				service.Endpoint, route.Name))
 		return
 	}
-	assert.JSONEqf(
+	rep.JSONEq(
 		t, route.Tester.BodyJSONString, string(b),
 		makeMsg("request body should match", service.Endpoint, route.Name))
 }
 
-func testPartOfHeader(t *testing.T, req *http.Request, service Service, route Route) {
+func testPartOfHeader(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
 	if route.Tester.PartOfHeader == nil {
 		return
 	}
@@ -159,20 +165,21 @@ func testPartOfHeader(t *testing.T, req *http.Request, service Service, route Ro
 	for k, v := range route.Tester.PartOfHeader {
 		a, ok := req.Header[k]
 		if !ok {
-			assert.Fail(
+			rep.Fail(
 				t, makeMsg(
 					"the following request header is required: "+k, service.Endpoint, route.Name))
 			return
 		}
 		if v != nil {
-			assert.Equal(
-				t, v, a,
+			// the values are passed as the header so that they can be redacted by the header name
+			rep.Equal(
+				t, http.Header{k: v}, http.Header{k: a},
 				makeMsg(fmt.Sprintf(`the request header "%s" should match`, k), service.Endpoint, route.Name))
 		}
 	}
 }
 
-func testPartOfQuery(t *testing.T, req *http.Request, service Service, route Route) {
+func testPartOfQuery(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
 	if route.Tester.PartOfQuery == nil {
 		return
 	}
@@ -181,15 +188,58 @@ func testPartOfQuery(t *testing.T, req *http.Request, service Service, route Rou
 	for k, v := range route.Tester.PartOfQuery {
 		a, ok := query[k]
 		if !ok {
-			assert.Fail(
+			rep.Fail(
 				t, makeMsg(
 					"the following request query is required: "+k, service.Endpoint, route.Name))
 			return
 		}
 		if v != nil {
-			assert.Equal(
-				t, v, a,
+			// the values are passed as the query so that they can be redacted by the key
+			rep.Equal(
+				t, url.Values{k: v}, url.Values{k: a},
 				makeMsg(fmt.Sprintf(`the request query "%s" should match`, k), service.Endpoint, route.Name))
 		}
 	}
 }
+
+func testBodyFile(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
+	if route.Tester.BodyFile == "" {
+		return
+	}
+
+	expected, contentType, err := readBodyFile(route.Tester.FS, route.Tester.BodyFile)
+	if err != nil {
+		rep.Fail(t, makeMsg(err.Error(), service.Endpoint, route.Name))
+		return
+	}
+	if req.Body == nil {
+		rep.Equal(
+			t, string(expected), "",
+			makeMsg("request body should match", service.Endpoint, route.Name))
+		return
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		rep.Fail(
+			t, makeMsg(
+				fmt.Sprintf("failed to read the request body: %v", err),
+				service.Endpoint, route.Name))
+		return
+	}
+	if isJSONContentType(contentType) {
+		rep.JSONEq(
+			t, string(expected), string(b),
+			makeMsg("request body should match", service.Endpoint, route.Name))
+		return
+	}
+	rep.Equal(
+		t, string(expected), string(b),
+		makeMsg("request body should match", service.Endpoint, route.Name))
+}
+
+func testAuth(t testing.TB, rep Reporter, req *http.Request, service Service, route Route) {
+	tester := route.Tester
+	if err := verifyAuth(req, tester.BasicAuth, tester.BearerToken, tester.HMAC, tester.AWSSigV4); err != nil {
+		rep.Fail(t, makeMsg(err.Error(), service.Endpoint, route.Name))
+	}
+}
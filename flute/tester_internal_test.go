@@ -72,7 +72,7 @@ func Test_testRequest(t *testing.T) { //nolint:funlen
 						"name": []string{"foo"},
 						"age":  []string{"10"},
 					},
-					Test: func(t *testing.T, req *http.Request, service Service, route Route) {},
+					Test: func(t testing.TB, req *http.Request, service Service, route Route) {},
 				},
 			},
 		},
@@ -108,7 +108,7 @@ func Test_testRequest(t *testing.T) { //nolint:funlen
 
 	for _, d := range data {
 		t.Run(d.title, func(t *testing.T) {
-			testRequest(t, d.req, d.service, d.route)
+			testRequest(t, TestifyReporter{}, d.req, d.service, d.route)
 		})
 	}
 }
@@ -172,7 +172,7 @@ func Test_testBodyString(t *testing.T) {
 
 	for _, d := range data {
 		t.Run(d.title, func(t *testing.T) {
-			testBodyString(t, d.req, d.service, d.route)
+			testBodyString(t, TestifyReporter{}, d.req, d.service, d.route)
 		})
 	}
 }
@@ -202,7 +202,7 @@ func Test_testPath(t *testing.T) {
 
 	for _, d := range data {
 		t.Run(d.title, func(t *testing.T) {
-			testPath(t, d.req, d.service, d.route)
+			testPath(t, TestifyReporter{}, d.req, d.service, d.route)
 		})
 	}
 }
@@ -230,7 +230,7 @@ func Test_testMethod(t *testing.T) {
 
 	for _, d := range data {
 		t.Run(d.title, func(t *testing.T) {
-			testMethod(t, d.req, d.service, d.route)
+			testMethod(t, TestifyReporter{}, d.req, d.service, d.route)
 		})
 	}
 }
@@ -270,7 +270,7 @@ func Test_testBodyJSON(t *testing.T) {
 
 	for _, d := range data {
 		t.Run(d.title, func(t *testing.T) {
-			testBodyJSON(t, d.req, d.service, d.route)
+			testBodyJSON(t, TestifyReporter{}, d.req, d.service, d.route)
 		})
 	}
 }
@@ -308,7 +308,7 @@ func Test_testBodyJSONString(t *testing.T) {
 
 	for _, d := range data {
 		t.Run(d.title, func(t *testing.T) {
-			testBodyJSONString(t, d.req, d.service, d.route)
+			testBodyJSONString(t, TestifyReporter{}, d.req, d.service, d.route)
 		})
 	}
 }
@@ -342,7 +342,7 @@ func Test_testPartOfHeader(t *testing.T) {
 
 	for _, d := range data {
 		t.Run(d.title, func(t *testing.T) {
-			testPartOfHeader(t, d.req, d.service, d.route)
+			testPartOfHeader(t, TestifyReporter{}, d.req, d.service, d.route)
 		})
 	}
 }
@@ -374,7 +374,7 @@ func Test_testPartOfQuery(t *testing.T) {
 
 	for _, d := range data {
 		t.Run(d.title, func(t *testing.T) {
-			testPartOfQuery(t, d.req, d.service, d.route)
+			testPartOfQuery(t, TestifyReporter{}, d.req, d.service, d.route)
 		})
 	}
 }
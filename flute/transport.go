@@ -1,15 +1,16 @@
 package flute
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
-	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
+	"github.com/getkin/kin-openapi/openapi3filter"
 )
 
 const (
@@ -21,19 +22,80 @@ query:
 header:
 %s
 body:
+%s
+curl:
 %s`
 )
 
 // RoundTrip implements http.RoundTripper.
 // RoundTrip traverses the matched route and run the test and returns response.
 func (transport Transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	for _, service := range transport.Services {
+	started := time.Now()
+	// the request body is read and closed first, so that it is closed and recorded even if RoundTrip fails.
+	// RoundTrip must not modify the request, so the hooks and the routes get the clone of the request.
+	clone, body, err := cloneRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := transport.roundTripChaos(clone, body)
+	if resp != nil && resp.Request == clone {
+		resp.Request = req
+	}
+	if transport.Recorder != nil {
+		// the response is recorded after Chaos changes it, so that the record is what the client received
+		transport.Recorder.record(req, body, resp, err, started, transport.redaction())
+	}
+	return resp, err
+}
+
+// roundTripChaos runs the hooks and handles the request and injects the failures of Chaos.
+func (transport Transport) roundTripChaos(req *http.Request, body []byte) (*http.Response, error) {
+	var chaos chaosDecision
+	if transport.Chaos != nil {
+		if transport.T != nil {
+			transport.Chaos.register(transport.T)
+		}
+		chaos = transport.Chaos.decide()
+		if err := chaos.before(req); err != nil {
+			return nil, err
+		}
+	}
+	for _, hook := range transport.Hooks.BeforeMatch {
+		if err := hook(req); err != nil {
+			return nil, err
+		}
+	}
+	if len(transport.Hooks.BeforeMatch) != 0 {
+		// the hooks may change the request body
+		b, err := readRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	resp, err := transport.roundTrip(req, body)
+	if err != nil || transport.Chaos == nil {
+		return resp, err
+	}
+	return chaos.after(req, resp)
+}
+
+func (transport Transport) roundTrip(req *http.Request, body []byte) (*http.Response, error) {
+	coverage := transport.coverage()
+	reg := coverage.registerTransport(transport)
+	logger := transport.logger()
+	redaction := transport.redaction()
+	logger.Info("flute: request", "method", req.Method, "url", redaction.url(req.URL))
+	for si, service := range transport.Services {
 		if !isMatchService(req, service) {
+			logger.Debug("flute: the service doesn't match", "service", service.Endpoint)
 			continue
 		}
-		for _, route := range service.Routes {
-			b, err := isMatch(req, route.Matcher)
-			if err != nil {
+		for i, route := range service.Routes {
+			resetRequestBody(req, body)
+			b, condition, err := matchCondition(req, route.Matcher)
+			var mismatch *mismatchError
+			if err != nil && !errors.As(err, &mismatch) {
 				if transport.T != nil {
 					transport.T.Logf("failed to check whether the route matches the request: %v", err)
 				} else {
@@ -41,25 +103,120 @@ func (transport Transport) RoundTrip(req *http.Request) (*http.Response, error)
 				}
 			}
 			if !b {
+				args := []any{"service", service.Endpoint, "route", route.Name, "route_index", i, "condition", condition}
+				if err != nil {
+					args = append(args, "error", err)
+				}
+				logger.Debug("flute: the route doesn't match", args...)
 				continue
 			}
-			// test
-			if transport.T != nil {
-				testRequest(transport.T, req, service, route)
+			logger.Info("flute: the route matches", "service", service.Endpoint, "route", route.Name, "route_index", i)
+			coverage.hit(reg.routes[si][i])
+			resetRequestBody(req, body)
+			resp, err := transport.roundTripRoute(req, body, service, route)
+			if err != nil {
+				logger.Info("flute: failed to respond", "service", service.Endpoint, "route", route.Name, "error", err)
+				return resp, err
 			}
-			// return response
-			return createHTTPResponse(req, route.Response)
+			logger.Info("flute: response", "service", service.Endpoint, "route", route.Name, "status", resp.StatusCode)
+			return resp, nil
 		}
 	}
+	resetRequestBody(req, body)
 	// no route matches the request
 	if transport.Transport != nil {
+		logger.Info("flute: no route matches the request, so the request is sent with Transport.Transport")
 		return transport.Transport.RoundTrip(req)
 	}
-	return noMatchedRouteRoundTrip(transport.T, req)
+	logger.Info("flute: no route matches the request")
+	return transport.unmatched(req, redaction)
+}
+
+// roundTripRoute runs the test of the matched route and returns the response.
+func (transport Transport) roundTripRoute(req *http.Request, body []byte, service Service, route Route) (*http.Response, error) {
+	for _, hook := range transport.Hooks.AfterMatch {
+		if err := hook(req, service, route); err != nil {
+			return nil, err
+		}
+		resetRequestBody(req, body)
+	}
+	redaction := transport.redaction()
+	rep := newCurlReporter(newRedactReporter(transport.reporter(), redaction, req.Header.Get("Content-Type")), req, body, redaction)
+	var input *openapi3filter.RequestValidationInput
+	if service.OpenAPI != nil {
+		in, err := service.OpenAPI.validateRequest(req)
+		if err != nil {
+			transport.reportError(rep, service, route, err)
+		}
+		input = in
+		resetRequestBody(req, body)
+	}
+	if transport.CookieChecker != nil {
+		if err := transport.CookieChecker.check(req); err != nil {
+			transport.reportError(rep, service, route, err)
+		}
+	}
+	// test
+	if transport.T != nil {
+		testRequest(transport.T, rep, req, service, route)
+	}
+	// return response
+	resetRequestBody(req, body)
+	resp, limited, err := transport.respond(req, service, route)
+	if err != nil {
+		return resp, err
+	}
+	// 429 Too Many Requests of the rate limiter isn't the route's response, so it isn't validated
+	if service.OpenAPI != nil && !limited {
+		if err := service.OpenAPI.validateResponse(input, resp); err != nil {
+			transport.reportError(rep, service, route, err)
+		}
+	}
+	if len(transport.Hooks.AfterResponse) != 0 {
+		// hooks can mutate the header without changing the route's header
+		resp.Header = cloneHeader(resp.Header)
+	}
+	for _, hook := range transport.Hooks.AfterResponse {
+		if err := hook(req, resp, service, route); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
 }
 
-func makeNoMatchedRouteMsg(t *testing.T, req *http.Request) string {
-	query := req.URL.Query()
+// respond returns the response of the route.
+// If the request exceeds the rate limit, respond returns 429 Too Many Requests and true.
+func (transport Transport) respond(req *http.Request, service Service, route Route) (*http.Response, bool, error) {
+	limit, hasLimiter := checkRateLimit(req, service, route)
+	if hasLimiter && !limit.Allowed {
+		return tooManyRequestsResponse(req, limit), true, nil
+	}
+	resp, err := createHTTPResponse(req, route.Response)
+	if err != nil {
+		return resp, false, err
+	}
+	if hasLimiter {
+		resp.Header = cloneHeader(resp.Header)
+		limit.setHeader(resp.Header)
+	}
+	if transport.CookieChecker != nil {
+		transport.CookieChecker.store(req, resp)
+	}
+	return resp, false, nil
+}
+
+// reportError reports the error as the test failure.
+// If transport.T is nil, reportError outputs the error to the standard error output.
+func (transport Transport) reportError(rep curlReporter, service Service, route Route, err error) {
+	if transport.T != nil {
+		rep.Fail(transport.T, makeMsg(err.Error(), service.Endpoint, route.Name))
+		return
+	}
+	fmt.Fprintln(os.Stderr, rep.withCurl(makeMsg(err.Error(), service.Endpoint, route.Name)))
+}
+
+func makeNoMatchedRouteMsg(t testing.TB, rep Reporter, req *http.Request, redaction *Redaction) string {
+	query := redaction.query(req.URL.Query())
 	qArr := make([]string, len(query))
 	i := 0
 	for k, v := range query {
@@ -67,39 +224,39 @@ func makeNoMatchedRouteMsg(t *testing.T, req *http.Request) string {
 		i++
 	}
 
-	hArr := make([]string, len(req.Header))
+	header := redaction.header(req.Header)
+	hArr := make([]string, len(header))
 	j := 0
-	for k, v := range req.Header {
+	for k, v := range header {
 		hArr[j] = "  " + k + ": " + strings.Join(v, ", ")
 		j++
 	}
 
-	body := ""
+	var body []byte
 	if req.Body != nil {
 		b, err := io.ReadAll(req.Body)
 		if err != nil {
-			assert.Nil(t, err, "failed to reqd the request body")
+			if t != nil {
+				rep.Fail(t, fmt.Sprintf("failed to read the request body: %v", err))
+			}
 		} else {
-			body = string(b)
+			body = b
 		}
 	}
 	return fmt.Sprintf(
 		noMatchedRouteMsgTpl,
-		req.URL.String(),
+		redaction.url(req.URL).String(),
 		req.Method,
 		strings.Join(qArr, "\n"),
 		strings.Join(hArr, "\n"),
-		body,
+		string(redaction.body(body, req.Header.Get("Content-Type"))),
+		makeCurlCommand(req, body, redaction),
 	)
 }
 
-func noMatchedRouteRoundTrip(t *testing.T, req *http.Request) (*http.Response, error) {
+func noMatchedRouteRoundTrip(t testing.TB, rep Reporter, req *http.Request, redaction *Redaction) (*http.Response, error) {
 	if t != nil {
-		require.Fail(t, makeNoMatchedRouteMsg(t, req))
+		rep.FailNow(t, makeNoMatchedRouteMsg(t, rep, req, redaction))
 	}
-	return &http.Response{
-		Request:    req,
-		StatusCode: http.StatusNotFound,
-		Body:       io.NopCloser(strings.NewReader(`{"message": "no route matches the request"}`)),
-	}, nil
+	return newResponse(req, http.StatusNotFound, nil, `{"message": "no route matches the request"}`, "application/json"), nil
 }
@@ -38,22 +38,24 @@ method: POST
 query:
   print: true
 header:
-  Authorization: token XXXXX
+  Authorization: REDACTED
 body:
-{"name": "foo", "email": "foo@example.com"}`,
+{"name": "foo", "email": "foo@example.com"}
+curl:
+curl -X POST 'http://example.com/users?print=true' -H 'Authorization: REDACTED' --data-raw '{"name": "foo", "email": "foo@example.com"}'`,
 		},
 	}
 
 	for _, d := range data {
 		t.Run(d.title, func(t *testing.T) {
-			require.Equal(t, d.exp, makeNoMatchedRouteMsg(t, d.req))
+			require.Equal(t, d.exp, makeNoMatchedRouteMsg(t, TestifyReporter{}, d.req, &DefaultRedaction))
 		})
 	}
 }
 
 func Test_noMatchedRouteRoundTrip(t *testing.T) {
 	data := []struct {
-		t          *testing.T
+		t          testing.TB
 		title      string
 		req        *http.Request
 		statusCode int
@@ -71,7 +73,7 @@ func Test_noMatchedRouteRoundTrip(t *testing.T) {
 
 	for _, d := range data {
 		t.Run(d.title, func(t *testing.T) {
-			resp, err := noMatchedRouteRoundTrip(d.t, d.req)
+			resp, err := noMatchedRouteRoundTrip(d.t, TestifyReporter{}, d.req, &DefaultRedaction)
 			if resp != nil && resp.Body != nil {
 				_, _ = io.Copy(io.Discard, resp.Body)
 				resp.Body.Close()
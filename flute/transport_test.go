@@ -195,6 +195,49 @@ func TestTransport_RoundTrip(t *testing.T) { //nolint:funlen
 				StatusCode: http.StatusNotFound,
 			},
 		},
+		{
+			title: "the request body can be read by multiple matchers",
+			req: &http.Request{
+				URL: &url.URL{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   "/users",
+				},
+				Method: http.MethodPost,
+				Body:   io.NopCloser(strings.NewReader(`bar`)),
+			},
+			transport: flute.Transport{
+				T: t,
+				Services: []flute.Service{
+					{
+						Endpoint: "http://example.com",
+						Routes: []flute.Route{
+							{
+								Matcher: flute.Matcher{
+									BodyString: "foo",
+								},
+							},
+							{
+								Matcher: flute.Matcher{
+									BodyString: "bar",
+								},
+								Tester: flute.Tester{
+									BodyString: "bar",
+								},
+								Response: flute.Response{
+									Base: http.Response{
+										StatusCode: http.StatusCreated,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			exp: &http.Response{
+				StatusCode: http.StatusCreated,
+			},
+		},
 		{
 			title: "transport.Transport is called",
 			req:   &http.Request{},
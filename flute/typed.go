@@ -0,0 +1,61 @@
+package flute
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// decodeRequestJSON decodes the request body as JSON.
+// The request body is restored so that it can be read again.
+func decodeRequestJSON[T any](req *http.Request) (T, error) {
+	var body T
+	b, err := readRequestBody(req)
+	if err != nil {
+		return body, err
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return body, fmt.Errorf("failed to decode the request body as %T: %w", body, err)
+	}
+	return body, nil
+}
+
+// TestJSON returns a function for Tester.Test which decodes the request body as JSON into T and calls fn.
+// If the request body can't be decoded, the test fails with TestifyReporter and fn isn't called.
+// To report the failure with another Reporter, use TestJSONWithReporter.
+//
+//	Tester: flute.Tester{
+//		Test: flute.TestJSON(func(t testing.TB, user User) {
+//			require.Equal(t, "foo", user.Name)
+//		}),
+//	},
+func TestJSON[T any](fn func(t testing.TB, body T)) func(testing.TB, *http.Request, Service, Route) {
+	return TestJSONWithReporter(nil, fn)
+}
+
+// TestJSONWithReporter is the same as TestJSON but reports the decode error with rep.
+// Pass the same Reporter as Transport.Reporter. If rep is nil, TestifyReporter is used.
+func TestJSONWithReporter[T any](rep Reporter, fn func(t testing.TB, body T)) func(testing.TB, *http.Request, Service, Route) {
+	rep = getReporter(rep)
+	return func(t testing.TB, req *http.Request, service Service, route Route) {
+		body, err := decodeRequestJSON[T](req)
+		if err != nil {
+			rep.Fail(t, makeMsg(err.Error(), service.Endpoint, route.Name))
+			return
+		}
+		fn(t, body)
+	}
+}
+
+// ResponseJSON returns a function for Response.Response which decodes the request body as JSON into T and calls fn.
+// If the request body can't be decoded, RoundTrip returns the error.
+func ResponseJSON[T any](fn func(req *http.Request, body T) (*http.Response, error)) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		body, err := decodeRequestJSON[T](req)
+		if err != nil {
+			return nil, err
+		}
+		return fn(req, body)
+	}
+}
@@ -0,0 +1,142 @@
+package flute_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+type (
+	user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	// errorfTB records Errorf calls instead of failing the test.
+	errorfTB struct {
+		testing.TB
+
+		msgs []string
+	}
+)
+
+func (tb *errorfTB) Helper() {}
+
+func (tb *errorfTB) Errorf(format string, args ...any) {
+	tb.msgs = append(tb.msgs, fmt.Sprintf(format, args...))
+}
+
+func TestTestJSON(t *testing.T) {
+	data := []struct {
+		title    string
+		body     string
+		exp      user
+		called   bool
+		failures int
+	}{
+		{
+			title:  "normal",
+			body:   `{"name": "foo"}`,
+			exp:    user{Name: "foo"},
+			called: true,
+		},
+		{
+			title:    "invalid JSON",
+			body:     `{"name": 1}`,
+			failures: 1,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			tb := &errorfTB{TB: t}
+			called := false
+			test := flute.TestJSON(func(_ testing.TB, body user) {
+				called = true
+				require.Equal(t, d.exp, body)
+			})
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://example.com/users", strings.NewReader(d.body))
+			require.NoError(t, err)
+			test(tb, req, flute.Service{Endpoint: "http://example.com"}, flute.Route{Name: "create a user"})
+			require.Equal(t, d.called, called)
+			require.Len(t, tb.msgs, d.failures)
+			// the request body can be read again
+			b, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.Equal(t, d.body, string(b))
+		})
+	}
+}
+
+func TestTestJSONWithReporter(t *testing.T) {
+	rep := &recordReporter{}
+	test := flute.TestJSONWithReporter(rep, func(testing.TB, user) {
+		require.Fail(t, "the function shouldn't be called")
+	})
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://example.com/users", strings.NewReader(`{"name": 1}`))
+	require.NoError(t, err)
+	test(t, req, flute.Service{Endpoint: "http://example.com"}, flute.Route{Name: "create a user"})
+	require.Len(t, rep.msgs, 1)
+}
+
+func TestResponseJSON(t *testing.T) {
+	data := []struct {
+		title string
+		body  string
+		exp   string
+		isErr bool
+	}{
+		{
+			title: "normal",
+			body:  `{"name": "foo"}`,
+			exp:   `{"id":10,"name":"foo"}`,
+		},
+		{
+			title: "invalid JSON",
+			body:  `{`,
+			isErr: true,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			transport := flute.Transport{
+				T: t,
+				Services: []flute.Service{
+					{
+						Endpoint: "http://example.com",
+						Routes: []flute.Route{
+							{
+								Response: flute.Response{
+									Response: flute.ResponseJSON(func(req *http.Request, body user) (*http.Response, error) {
+										body.ID = 10
+										return &http.Response{
+											Request:    req,
+											StatusCode: http.StatusCreated,
+											Body:       io.NopCloser(strings.NewReader(fmt.Sprintf(`{"id":%d,"name":%q}`, body.ID, body.Name))),
+										}, nil
+									}),
+								},
+							},
+						},
+					},
+				},
+			}
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://example.com/users", strings.NewReader(d.body))
+			require.NoError(t, err)
+			resp, err := transport.RoundTrip(req)
+			if d.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, d.exp, string(b))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package flute
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrNoRouteMatched is the error which RoundTrip returns when no route matches the request
+// and Transport.UnmatchedPolicy is UnmatchedReturnError.
+// The returned error is *NoRouteMatchedError, which errors.Is reports as ErrNoRouteMatched.
+var ErrNoRouteMatched = errors.New("flute: no route matches the request")
+
+// UnmatchedPolicy is the policy of the transport when no route matches the request.
+type UnmatchedPolicy int
+
+const (
+	// UnmatchedFailNow fails the test with FailNow, which stops the test goroutine.
+	// If Transport.T is nil, RoundTrip returns 404 Not Found.
+	// This is the default policy.
+	UnmatchedFailNow UnmatchedPolicy = iota
+	// UnmatchedFailAtCleanup returns 404 Not Found and fails the test at the cleanup of Transport.T.
+	// Unlike UnmatchedFailNow, it is safe to send requests from goroutines other than the test goroutine
+	// and the later failures are reported too.
+	// If Transport.T is nil, RoundTrip returns 404 Not Found.
+	UnmatchedFailAtCleanup
+	// UnmatchedReturnError returns *NoRouteMatchedError from RoundTrip.
+	UnmatchedReturnError
+	// UnmatchedRespond returns the response of Transport.UnmatchedResponse.
+	// If Transport.UnmatchedResponse is nil, RoundTrip returns 404 Not Found.
+	UnmatchedRespond
+)
+
+// NoRouteMatchedError is the error which RoundTrip returns when no route matches the request.
+// errors.Is(err, ErrNoRouteMatched) returns true.
+type NoRouteMatchedError struct {
+	// Method is the request method.
+	Method string
+	// URL is the request URL whose secrets are redacted.
+	URL string
+	// Detail is the description of the request such as the query, header, and body whose secrets are redacted.
+	Detail string
+}
+
+func (e *NoRouteMatchedError) Error() string {
+	return ErrNoRouteMatched.Error() + ": " + e.Method + " " + e.URL
+}
+
+// Is returns whether target is ErrNoRouteMatched.
+func (e *NoRouteMatchedError) Is(target error) bool {
+	return target == ErrNoRouteMatched
+}
+
+// unmatched handles the request which no route matches according to transport.UnmatchedPolicy.
+func (transport Transport) unmatched(req *http.Request, redaction *Redaction) (*http.Response, error) {
+	switch transport.UnmatchedPolicy {
+	case UnmatchedFailAtCleanup:
+		if t := transport.T; t != nil {
+			rep := transport.reporter()
+			msg := makeNoMatchedRouteMsg(t, rep, req, redaction)
+			t.Cleanup(func() {
+				rep.Fail(t, msg)
+			})
+		}
+		return noMatchedRouteRoundTrip(nil, nil, req, redaction)
+	case UnmatchedReturnError:
+		return nil, &NoRouteMatchedError{
+			Method: req.Method,
+			URL:    redaction.url(req.URL).String(),
+			Detail: makeNoMatchedRouteMsg(transport.T, transport.reporter(), req, redaction),
+		}
+	case UnmatchedRespond:
+		if transport.UnmatchedResponse != nil {
+			return transport.UnmatchedResponse(req)
+		}
+		return noMatchedRouteRoundTrip(nil, nil, req, redaction)
+	default:
+		return noMatchedRouteRoundTrip(transport.T, transport.reporter(), req, redaction)
+	}
+}
@@ -0,0 +1,98 @@
+package flute_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestTransport_UnmatchedPolicy(t *testing.T) { //nolint:funlen
+	data := []struct {
+		title      string
+		policy     flute.UnmatchedPolicy
+		response   func(req *http.Request) (*http.Response, error)
+		statusCode int
+		isErr      bool
+		// failures is the number of the failures reported in the subtest
+		failures int
+		// cleanupFailures is the number of the failures reported at the cleanup of the subtest
+		cleanupFailures int
+	}{
+		{
+			title:      "fail now",
+			policy:     flute.UnmatchedFailNow,
+			statusCode: http.StatusNotFound,
+			failures:   1,
+		},
+		{
+			title:           "fail at cleanup",
+			policy:          flute.UnmatchedFailAtCleanup,
+			statusCode:      http.StatusNotFound,
+			cleanupFailures: 1,
+		},
+		{
+			title:  "return error",
+			policy: flute.UnmatchedReturnError,
+			isErr:  true,
+		},
+		{
+			title:  "respond",
+			policy: flute.UnmatchedRespond,
+			response: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					Request:    req,
+					StatusCode: http.StatusTeapot,
+					Body:       io.NopCloser(strings.NewReader("")),
+				}, nil
+			},
+			statusCode: http.StatusTeapot,
+		},
+		{
+			title:      "respond without UnmatchedResponse",
+			policy:     flute.UnmatchedRespond,
+			statusCode: http.StatusNotFound,
+		},
+	}
+	for _, d := range data {
+		rep := &recordReporter{}
+		t.Run(d.title, func(t *testing.T) {
+			client := &http.Client{
+				Transport: flute.Transport{
+					T:                 t,
+					Reporter:          rep,
+					UnmatchedPolicy:   d.policy,
+					UnmatchedResponse: d.response,
+				},
+			}
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/users?token=XXXXX", nil)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			if d.isErr {
+				require.ErrorIs(t, err, flute.ErrNoRouteMatched)
+				var e *flute.NoRouteMatchedError
+				require.ErrorAs(t, err, &e)
+				require.Equal(t, "http://example.com/users?token=REDACTED", e.URL)
+				require.Contains(t, e.Detail, "no route matches the request.")
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, d.statusCode, resp.StatusCode)
+			require.Len(t, rep.msgs, d.failures)
+		})
+		require.Len(t, rep.msgs, d.failures+d.cleanupFailures)
+	}
+}
+
+func TestNoRouteMatchedError(t *testing.T) {
+	var err error = &flute.NoRouteMatchedError{
+		Method: http.MethodGet,
+		URL:    "http://example.com/users",
+	}
+	require.ErrorIs(t, err, flute.ErrNoRouteMatched)
+	require.Equal(t, "flute: no route matches the request: GET http://example.com/users", err.Error())
+}
@@ -0,0 +1,172 @@
+package flute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Validate reports the configuration mistakes of the services.
+// In addition to the mistakes which Service.Validate reports, Validate reports services of the same endpoint.
+// All mistakes are joined.
+func (transport Transport) Validate() error {
+	var errs []error
+	endpoints := make(map[string]struct{}, len(transport.Services))
+	for _, service := range transport.Services {
+		if _, ok := endpoints[service.Endpoint]; ok {
+			errs = append(errs, fmt.Errorf("service %s: the endpoint is duplicated", service.Endpoint))
+		}
+		endpoints[service.Endpoint] = struct{}{}
+		if err := service.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate reports the configuration mistakes of the service such as
+// the endpoint including a path, duplicated route names, and routes shadowed by earlier routes.
+// All mistakes are joined.
+func (service Service) Validate() error {
+	var errs []error
+	if err := validateEndpoint(service.Endpoint); err != nil {
+		errs = append(errs, err)
+	}
+	names := make(map[string]int, len(service.Routes))
+	for i, route := range service.Routes {
+		label := routeLabel(route, i)
+		if route.Name != "" {
+			if j, ok := names[route.Name]; ok {
+				errs = append(errs, fmt.Errorf("%s: the route name is the same as route %d", label, j))
+			} else {
+				names[route.Name] = i
+			}
+		}
+		for _, err := range validateRoute(route) {
+			errs = append(errs, fmt.Errorf("%s: %w", label, err))
+		}
+		for j, earlier := range service.Routes[:i] {
+			if shadows(earlier.Matcher, route.Matcher) {
+				errs = append(errs, fmt.Errorf("%s: the route is unreachable because %s matches all requests which the route matches", label, routeLabel(earlier, j)))
+				break
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("service %s: %w", service.Endpoint, err)
+	}
+	return nil
+}
+
+// validateEndpoint checks whether the endpoint consists of the scheme and host only.
+// Otherwise the service never matches requests.
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("parse the endpoint: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("the endpoint must include the scheme and host: %s", endpoint)
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil || u.ForceQuery {
+		return fmt.Errorf("the endpoint must consist of the scheme and host only: %s", endpoint)
+	}
+	return nil
+}
+
+func routeLabel(route Route, index int) string {
+	if route.Name == "" {
+		return "route " + strconv.Itoa(index)
+	}
+	return fmt.Sprintf("route %d (%s)", index, route.Name)
+}
+
+func validateRoute(route Route) []error {
+	var errs []error
+	if s := route.Matcher.BodyJSONString; s != "" && !json.Valid([]byte(s)) {
+		errs = append(errs, errors.New("BodyJSONString of the matcher isn't valid JSON"))
+	}
+	if s := route.Tester.BodyJSONString; s != "" && !json.Valid([]byte(s)) {
+		errs = append(errs, errors.New("BodyJSONString of the tester isn't valid JSON"))
+	}
+	resp := route.Response
+	n := 0
+	for _, set := range []bool{resp.BodyJSON != nil, resp.BodyString != "", resp.BodyFile != ""} {
+		if set {
+			n++
+		}
+	}
+	if n > 1 {
+		errs = append(errs, errors.New("only one of BodyJSON, BodyString and BodyFile of the response should be set"))
+	}
+	return errs
+}
+
+// shadows returns whether earlier matches all requests which later matches.
+// shadows returns false if it can't be determined, for example when earlier has Match.
+func shadows(earlier, later Matcher) bool {
+	if earlier.Method != "" && !strings.EqualFold(earlier.Method, later.Method) {
+		return false
+	}
+	if earlier.Path != "" && earlier.Path != later.Path {
+		return false
+	}
+	if earlier.PathTemplate != "" {
+		p := later.Path
+		if p == "" {
+			p = later.PathTemplate
+		}
+		// a template segment of later such as "{id}" is non-empty, so it is matched with a template segment of earlier
+		if p == "" || !matchTemplate(earlier.PathTemplate, p) {
+			return false
+		}
+	}
+	if !includesValues(earlier.PartOfHeader, later.PartOfHeader, later.Header) {
+		return false
+	}
+	if !includesValues(earlier.PartOfQuery, later.PartOfQuery, later.Query) {
+		return false
+	}
+	if !includesValues(earlier.PartOfCookie, later.PartOfCookie) {
+		return false
+	}
+	// the other conditions must be unset or the same
+	e := reflect.ValueOf(earlier)
+	l := reflect.ValueOf(later)
+	for i := range e.NumField() {
+		switch e.Type().Field(i).Name {
+		case "Method", "Path", "PathTemplate", "PartOfHeader", "PartOfQuery", "PartOfCookie":
+			continue
+		}
+		if f := e.Field(i); !f.IsZero() && !reflect.DeepEqual(f.Interface(), l.Field(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+// includesValues returns whether all requests which have the values of any of laters have the values of earlier.
+// A nil value means that only the key is checked.
+func includesValues[M ~map[string][]string](earlier M, laters ...M) bool {
+	for k, v := range earlier {
+		found := false
+		for _, later := range laters {
+			lv, ok := later[k]
+			if !ok {
+				continue
+			}
+			if v == nil || (lv != nil && reflect.DeepEqual(v, lv)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
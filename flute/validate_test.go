@@ -0,0 +1,167 @@
+package flute_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuki-shunsuke/flute/v2/flute"
+)
+
+func TestTransport_Validate(t *testing.T) { //nolint:funlen
+	data := []struct {
+		title    string
+		services []flute.Service
+		exp      string
+	}{
+		{
+			title: "valid",
+			services: []flute.Service{
+				{
+					Endpoint: "http://example.com",
+					Routes: []flute.Route{
+						{
+							Name: "get a user",
+							Matcher: flute.Matcher{
+								Method:       http.MethodGet,
+								PathTemplate: "/users/{id}",
+							},
+						},
+						{
+							Name: "get a user with a header",
+							Matcher: flute.Matcher{
+								Method: http.MethodGet,
+								Path:   "/users",
+								PartOfHeader: http.Header{
+									"X-Foo": []string{"foo"},
+								},
+							},
+						},
+						{
+							Name: "list users",
+							Matcher: flute.Matcher{
+								Method: http.MethodGet,
+								Path:   "/users",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			title: "endpoint",
+			services: []flute.Service{
+				{Endpoint: "http://example.com/api"},
+				{Endpoint: "http://example.com?foo=bar"},
+				{Endpoint: "example.com"},
+				{Endpoint: "http://api.example.com"},
+				{Endpoint: "http://api.example.com"},
+			},
+			exp: `service http://example.com/api: the endpoint must consist of the scheme and host only: http://example.com/api
+service http://example.com?foo=bar: the endpoint must consist of the scheme and host only: http://example.com?foo=bar
+service example.com: the endpoint must include the scheme and host: example.com
+service http://api.example.com: the endpoint is duplicated`,
+		},
+		{
+			title: "routes",
+			services: []flute.Service{
+				{
+					Endpoint: "http://example.com",
+					Routes: []flute.Route{
+						{
+							Name: "create a user",
+							Matcher: flute.Matcher{
+								BodyJSONString: `{`,
+							},
+							Response: flute.Response{
+								BodyJSON:   map[string]any{"id": 10},
+								BodyString: `{"id": 10}`,
+							},
+						},
+						{
+							Name: "create a user",
+							Tester: flute.Tester{
+								BodyJSONString: `{`,
+							},
+						},
+					},
+				},
+			},
+			exp: `service http://example.com: route 0 (create a user): BodyJSONString of the matcher isn't valid JSON
+route 0 (create a user): only one of BodyJSON, BodyString and BodyFile of the response should be set
+route 1 (create a user): the route name is the same as route 0
+route 1 (create a user): BodyJSONString of the tester isn't valid JSON`,
+		},
+		{
+			title: "shadowed routes",
+			services: []flute.Service{
+				{
+					Endpoint: "http://example.com",
+					Routes: []flute.Route{
+						{
+							Name: "get a user",
+							Matcher: flute.Matcher{
+								Method:       http.MethodGet,
+								PathTemplate: "/users/{id}",
+								PartOfQuery: url.Values{
+									"fields": nil,
+								},
+							},
+						},
+						{
+							Name: "get the user 10",
+							Matcher: flute.Matcher{
+								Method: http.MethodGet,
+								Path:   "/users/10",
+								Query: url.Values{
+									"fields": []string{"name"},
+								},
+							},
+						},
+						{
+							Matcher: flute.Matcher{
+								Method: http.MethodPost,
+							},
+						},
+						{
+							Name: "create a user",
+							Matcher: flute.Matcher{
+								Method: http.MethodPost,
+								Path:   "/users",
+							},
+						},
+						{
+							Name: "create a user with a custom matcher",
+							Matcher: flute.Matcher{
+								Match: func(*http.Request) (bool, error) {
+									return true, nil
+								},
+							},
+						},
+						{
+							Name: "unreachable",
+							Matcher: flute.Matcher{
+								Method: http.MethodPost,
+								Path:   "/admin",
+							},
+						},
+					},
+				},
+			},
+			exp: `service http://example.com: route 1 (get the user 10): the route is unreachable because route 0 (get a user) matches all requests which the route matches
+route 3 (create a user): the route is unreachable because route 2 matches all requests which the route matches
+route 5 (unreachable): the route is unreachable because route 2 matches all requests which the route matches`,
+		},
+	}
+	for _, d := range data {
+		t.Run(d.title, func(t *testing.T) {
+			err := flute.Transport{Services: d.services}.Validate()
+			if d.exp == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, d.exp)
+		})
+	}
+}